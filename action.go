@@ -0,0 +1,493 @@
+package zabbix
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type (
+	// ActionEvalType condition evaluation method of the action filter
+	// see "evaltype" in https://www.zabbix.com/documentation/6.0/manual/api/reference/action/object#action-filter
+	ActionEvalType int
+
+	// ConditionType type of an action condition
+	// see "conditiontype" in https://www.zabbix.com/documentation/6.0/manual/api/reference/action/object#action-filter-condition
+	ConditionType int
+
+	// ConditionOperator comparison of an action condition
+	// see "operator" in https://www.zabbix.com/documentation/6.0/manual/api/reference/action/object#action-filter-condition
+	ConditionOperator int
+
+	// TagOperator comparison of an action tag condition
+	// see "operator" in https://www.zabbix.com/documentation/6.0/manual/api/reference/action/object#action-filter-condition
+	TagOperator int
+
+	// OperationType type of an action operation
+	// see "operationtype" in https://www.zabbix.com/documentation/6.0/manual/api/reference/action/object#action-operation
+	OperationType int
+)
+
+const (
+	// ActionAndOr and/or (default)
+	ActionAndOr ActionEvalType = 0
+	// ActionAnd and
+	ActionAnd ActionEvalType = 1
+	// ActionOr or
+	ActionOr ActionEvalType = 2
+	// ActionCustom custom expression in Formula
+	ActionCustom ActionEvalType = 3
+)
+
+const (
+	// ConditionHostGroup host group
+	ConditionHostGroup ConditionType = 0
+	// ConditionHost host
+	ConditionHost ConditionType = 1
+	// ConditionTrigger trigger
+	ConditionTrigger ConditionType = 2
+	// ConditionTriggerName event name
+	ConditionTriggerName ConditionType = 3
+	// ConditionTriggerSeverity trigger severity
+	ConditionTriggerSeverity ConditionType = 4
+	// ConditionTemplate template
+	ConditionTemplate ConditionType = 13
+	// ConditionProxy proxy, for discovery and auto-registration actions
+	ConditionProxy ConditionType = 20
+	// ConditionHostName host name, for auto-registration actions
+	ConditionHostName ConditionType = 22
+	// ConditionHostMetadata host metadata, for auto-registration actions
+	ConditionHostMetadata ConditionType = 24
+	// ConditionTag event tag
+	ConditionTag ConditionType = 25
+	// ConditionTagValue event tag value
+	ConditionTagValue ConditionType = 26
+)
+
+const (
+	// ConditionEqual = (default)
+	ConditionEqual ConditionOperator = 0
+	// ConditionNotEqual <>
+	ConditionNotEqual ConditionOperator = 1
+	// ConditionLike contains
+	ConditionLike ConditionOperator = 2
+	// ConditionNotLike does not contain
+	ConditionNotLike ConditionOperator = 3
+	// ConditionIn in
+	ConditionIn ConditionOperator = 4
+	// ConditionGreaterEqual >=
+	ConditionGreaterEqual ConditionOperator = 5
+	// ConditionLessEqual <=
+	ConditionLessEqual ConditionOperator = 6
+	// ConditionNotIn not in
+	ConditionNotIn ConditionOperator = 7
+	// ConditionMatches matches regular expression
+	ConditionMatches ConditionOperator = 8
+	// ConditionNotMatches does not match regular expression
+	ConditionNotMatches ConditionOperator = 9
+	// ConditionYes yes
+	ConditionYes ConditionOperator = 10
+	// ConditionNo no
+	ConditionNo ConditionOperator = 11
+)
+
+const (
+	// TagEqual equals (default)
+	TagEqual TagOperator = TagOperator(ConditionEqual)
+	// TagNotEqual does not equal
+	TagNotEqual TagOperator = TagOperator(ConditionNotEqual)
+	// TagLike contains
+	TagLike TagOperator = TagOperator(ConditionLike)
+	// TagNotLike does not contain
+	TagNotLike TagOperator = TagOperator(ConditionNotLike)
+)
+
+const (
+	// OperationMessage send message
+	OperationMessage OperationType = 0
+	// OperationScript global script
+	OperationScript OperationType = 1
+	// OperationAddHost add host
+	OperationAddHost OperationType = 2
+	// OperationRemoveHost remove host
+	OperationRemoveHost OperationType = 3
+	// OperationAddToGroup add to host group
+	OperationAddToGroup OperationType = 4
+	// OperationRemoveFromGroup remove from host group
+	OperationRemoveFromGroup OperationType = 5
+	// OperationLinkTemplate link to template
+	OperationLinkTemplate OperationType = 6
+	// OperationUnlinkTemplate unlink from template
+	OperationUnlinkTemplate OperationType = 7
+	// OperationEnableHost enable host
+	OperationEnableHost OperationType = 8
+	// OperationDisableHost disable host
+	OperationDisableHost OperationType = 9
+	// OperationInventoryMode set host inventory mode
+	OperationInventoryMode OperationType = 10
+)
+
+// ActionCondition represent Zabbix action filter condition object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/action/object#action-filter-condition
+type ActionCondition struct {
+	ConditionID   string            `json:"conditionid,omitempty"`
+	ConditionType ConditionType     `json:"conditiontype,string"`
+	Operator      ConditionOperator `json:"operator,string"`
+	Value         string            `json:"value"`
+	Value2        string            `json:"value2,omitempty"`
+	FormulaID     string            `json:"formulaid,omitempty"`
+}
+
+// ActionConditions is an array of ActionCondition
+type ActionConditions []ActionCondition
+
+// ActionFilter represent Zabbix action filter object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/action/object#action-filter
+type ActionFilter struct {
+	EvalType   ActionEvalType   `json:"evaltype,string"`
+	Formula    string           `json:"formula,omitempty"`
+	Conditions ActionConditions `json:"conditions"`
+}
+
+// ActionOpGroup host group of an operation
+type ActionOpGroup struct {
+	GroupID string `json:"groupid"`
+}
+
+// ActionOpTemplate template of an operation
+type ActionOpTemplate struct {
+	TemplateID string `json:"templateid"`
+}
+
+// ActionOperation represent Zabbix action operation object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/action/object#action-operation
+type ActionOperation struct {
+	OperationID   string             `json:"operationid,omitempty"`
+	OperationType OperationType      `json:"operationtype,string"`
+	OpGroup       []ActionOpGroup    `json:"opgroup,omitempty"`
+	OpTemplate    []ActionOpTemplate `json:"optemplate,omitempty"`
+}
+
+// ActionOperations is an array of ActionOperation
+type ActionOperations []ActionOperation
+
+// Action represent Zabbix action object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/action/object
+type Action struct {
+	ActionID    string           `json:"actionid,omitempty"`
+	Name        string           `json:"name"`
+	EventSource EventSource      `json:"eventsource,string"`
+	Status      StatusType       `json:"status,string"`
+	EscPeriod   string           `json:"esc_period,omitempty"`
+	Filter      *ActionFilter    `json:"filter,omitempty"`
+	Operations  ActionOperations `json:"operations,omitempty"`
+}
+
+// Actions is an array of Action
+type Actions []Action
+
+// NewTagActionCondition Builds a condition on the events tags, labeled formulaID
+// for use in a custom Formula. With an empty value the tag name is compared to tag,
+// otherwise the value of tag is compared to value.
+func NewTagActionCondition(formulaID, tag, value string, op TagOperator) ActionCondition {
+	c := ActionCondition{
+		ConditionType: ConditionTag,
+		Operator:      ConditionOperator(op),
+		Value:         tag,
+		FormulaID:     formulaID,
+	}
+	if value != "" {
+		c.ConditionType = ConditionTagValue
+		c.Value = value
+		c.Value2 = tag
+	}
+	return c
+}
+
+// NewAutoRegistrationAction Builds an auto-registration action adding the agents
+// whose host metadata contains metadata, linked to the templates and in the groups.
+func NewAutoRegistrationAction(name, metadata string, templateIDs, groupIDs []string) Action {
+	action := Action{
+		Name:        name,
+		EventSource: EventSourceAutoRegistration,
+		Filter:      &ActionFilter{EvalType: ActionAndOr, Conditions: ActionConditions{}},
+		Operations:  ActionOperations{{OperationType: OperationAddHost}},
+	}
+	if metadata != "" {
+		action.Filter.Conditions = append(action.Filter.Conditions, ActionCondition{
+			ConditionType: ConditionHostMetadata,
+			Operator:      ConditionLike,
+			Value:         metadata,
+		})
+	}
+	if len(groupIDs) > 0 {
+		op := ActionOperation{OperationType: OperationAddToGroup}
+		for _, id := range groupIDs {
+			op.OpGroup = append(op.OpGroup, ActionOpGroup{id})
+		}
+		action.Operations = append(action.Operations, op)
+	}
+	if len(templateIDs) > 0 {
+		op := ActionOperation{OperationType: OperationLinkTemplate}
+		for _, id := range templateIDs {
+			op.OpTemplate = append(op.OpTemplate, ActionOpTemplate{id})
+		}
+		action.Operations = append(action.Operations, op)
+	}
+	return action
+}
+
+// ActionsGet Wrapper for action.get
+// Filter and operations are selected unless asked otherwise.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/action/get
+func (api *API) ActionsGet(params Params) (res Actions, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	if _, present := params["selectFilter"]; !present {
+		params["selectFilter"] = "extend"
+	}
+	if _, present := params["selectOperations"]; !present {
+		params["selectOperations"] = "extend"
+	}
+	err = api.CallWithErrorParse("action.get", params, &res)
+	return
+}
+
+// ActionsCreate Wrapper for action.create
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/action/create
+func (api *API) ActionsCreate(actions Actions) (err error) {
+	response, err := api.CallWithError("action.create", actions)
+	if err != nil {
+		return
+	}
+
+	actionids, err := resultIDs(response, "actionids")
+	if err != nil {
+		return
+	}
+	if len(actionids) != len(actions) {
+		return &ExpectedMore{len(actions), len(actionids)}
+	}
+	for i, id := range actionids {
+		actions[i].ActionID = id
+	}
+	return
+}
+
+// ActionsUpdate Wrapper for action.update
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/action/update
+func (api *API) ActionsUpdate(actions Actions) (err error) {
+	_, err = api.CallWithError("action.update", actions)
+	return
+}
+
+// ActionsDelete Wrapper for action.delete
+// Cleans ActionID in all actions elements if call succeed.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/action/delete
+func (api *API) ActionsDelete(actions Actions) (err error) {
+	ids := make([]string, len(actions))
+	for i, action := range actions {
+		ids[i] = action.ActionID
+	}
+
+	err = api.ActionsDeleteByIds(ids)
+	if err == nil {
+		for i := range actions {
+			actions[i].ActionID = ""
+		}
+	}
+	return
+}
+
+// ActionsDeleteByIds Wrapper for action.delete
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/action/delete
+func (api *API) ActionsDeleteByIds(ids []string) (err error) {
+	response, err := api.CallWithError("action.delete", ids)
+	if err != nil {
+		return
+	}
+
+	actionids, err := resultIDs(response, "actionids")
+	if err != nil {
+		return
+	}
+	if len(ids) != len(actionids) {
+		err = &ExpectedMore{len(ids), len(actionids)}
+	}
+	return
+}
+
+// MatchAutoRegistration Returns the enabled auto-registration actions an agent
+// with the given host metadata and host name would trigger.
+// The filters are evaluated client-side; conditions on anything else than the host
+// name and metadata, such as the proxy, can't be known here and are considered unmet.
+func (api *API) MatchAutoRegistration(metadata, hostname string) (res []Action, err error) {
+	actions, err := api.ActionsGet(Params{
+		"filter": map[string]interface{}{"eventsource": EventSourceAutoRegistration, "status": Enabled},
+	})
+	if err != nil {
+		return
+	}
+	for _, action := range actions {
+		var ok bool
+		if ok, err = action.Filter.matches(metadata, hostname); err != nil {
+			return nil, fmt.Errorf("action %s: %s", action.Name, err)
+		}
+		if ok {
+			res = append(res, action)
+		}
+	}
+	return
+}
+
+// matches Evaluates the filter for an auto-registration event, an empty filter always matches
+func (f *ActionFilter) matches(metadata, hostname string) (bool, error) {
+	if f == nil || len(f.Conditions) == 0 {
+		return true, nil
+	}
+	results := make([]bool, len(f.Conditions))
+	for i, c := range f.Conditions {
+		ok, err := c.matches(metadata, hostname)
+		if err != nil {
+			return false, err
+		}
+		results[i] = ok
+	}
+
+	switch f.EvalType {
+	case ActionAnd:
+		for _, ok := range results {
+			if !ok {
+				return false, nil
+			}
+		}
+		return true, nil
+	case ActionOr:
+		for _, ok := range results {
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	case ActionCustom:
+		byID := make(map[string]bool, len(results))
+		for i, c := range f.Conditions {
+			byID[c.FormulaID] = results[i]
+		}
+		return evalFormula(f.Formula, byID)
+	}
+
+	// and/or: conditions of the same type are or'ed, different types and'ed
+	byType := map[ConditionType]bool{}
+	for i, c := range f.Conditions {
+		byType[c.ConditionType] = byType[c.ConditionType] || results[i]
+	}
+	for _, ok := range byType {
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matches Evaluates the condition for an auto-registration event
+func (c ActionCondition) matches(metadata, hostname string) (bool, error) {
+	var subject string
+	switch c.ConditionType {
+	case ConditionHostMetadata:
+		subject = metadata
+	case ConditionHostName:
+		subject = hostname
+	default:
+		return false, nil
+	}
+
+	switch c.Operator {
+	case ConditionEqual:
+		return subject == c.Value, nil
+	case ConditionNotEqual:
+		return subject != c.Value, nil
+	case ConditionLike:
+		return strings.Contains(subject, c.Value), nil
+	case ConditionNotLike:
+		return !strings.Contains(subject, c.Value), nil
+	case ConditionMatches, ConditionNotMatches:
+		re, err := regexp.Compile(c.Value)
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(subject) == (c.Operator == ConditionMatches), nil
+	}
+	return false, fmt.Errorf("unsupported operator %d", c.Operator)
+}
+
+// evalFormula Evaluates a custom filter formula such as "A and (B or not C)"
+func evalFormula(formula string, values map[string]bool) (bool, error) {
+	p := &formulaParser{tokens: formulaTokenRegexp.FindAllString(formula, -1), values: values}
+	res, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q in formula %q", p.tokens[p.pos], formula)
+	}
+	return res, err
+}
+
+var formulaTokenRegexp = regexp.MustCompile(`[()]|[A-Za-z]+`)
+
+// formulaParser recursive descent parser of custom filter formulas
+type formulaParser struct {
+	tokens []string
+	pos    int
+	values map[string]bool
+}
+
+func (p *formulaParser) next() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *formulaParser) or() (bool, error) {
+	res, err := p.and()
+	for err == nil && p.next() == "or" {
+		p.pos++
+		var ok bool
+		ok, err = p.and()
+		res = res || ok
+	}
+	return res, err
+}
+
+func (p *formulaParser) and() (bool, error) {
+	res, err := p.not()
+	for err == nil && p.next() == "and" {
+		p.pos++
+		var ok bool
+		ok, err = p.not()
+		res = res && ok
+	}
+	return res, err
+}
+
+func (p *formulaParser) not() (bool, error) {
+	token := p.next()
+	p.pos++
+	switch token {
+	case "not":
+		res, err := p.not()
+		return !res, err
+	case "(":
+		res, err := p.or()
+		if err == nil && p.next() != ")" {
+			err = fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return res, err
+	}
+	res, ok := p.values[token]
+	if !ok {
+		return false, fmt.Errorf("unknown condition %q in formula", token)
+	}
+	return res, nil
+}
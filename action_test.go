@@ -0,0 +1,130 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestNewAutoRegistrationAction(t *testing.T) {
+	action := zapi.NewAutoRegistrationAction("Linux agents", "Linux", []string{"10001"}, []string{"2"})
+
+	b, err := json.Marshal(action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]interface{}
+	if err = json.Unmarshal(b, &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"name":        "Linux agents",
+		"eventsource": "2",
+		"status":      "0",
+		"filter": map[string]interface{}{
+			"evaltype": "0",
+			"conditions": []interface{}{
+				map[string]interface{}{"conditiontype": "24", "operator": "2", "value": "Linux"},
+			},
+		},
+		"operations": []interface{}{
+			map[string]interface{}{"operationtype": "2"},
+			map[string]interface{}{"operationtype": "4", "opgroup": []interface{}{map[string]interface{}{"groupid": "2"}}},
+			map[string]interface{}{"operationtype": "6", "optemplate": []interface{}{map[string]interface{}{"templateid": "10001"}}},
+		},
+	}
+	if !reflect.DeepEqual(payload, expected) {
+		t.Errorf("Bad action payload: %s", b)
+	}
+}
+
+func TestNewTagActionCondition(t *testing.T) {
+	filter := zapi.ActionFilter{
+		EvalType: zapi.ActionCustom,
+		Formula:  "A and (B or C)",
+		Conditions: zapi.ActionConditions{
+			zapi.NewTagActionCondition("A", "scope", "", zapi.TagEqual),
+			zapi.NewTagActionCondition("B", "service", "web", zapi.TagEqual),
+			zapi.NewTagActionCondition("C", "service", "db", zapi.TagLike),
+		},
+	}
+
+	b, err := json.Marshal(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]interface{}
+	if err = json.Unmarshal(b, &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"evaltype": "3",
+		"formula":  "A and (B or C)",
+		"conditions": []interface{}{
+			map[string]interface{}{"conditiontype": "25", "operator": "0", "value": "scope", "formulaid": "A"},
+			map[string]interface{}{"conditiontype": "26", "operator": "0", "value": "web", "value2": "service", "formulaid": "B"},
+			map[string]interface{}{"conditiontype": "26", "operator": "2", "value": "db", "value2": "service", "formulaid": "C"},
+		},
+	}
+	if !reflect.DeepEqual(payload, expected) {
+		t.Errorf("Bad filter payload: %s", b)
+	}
+}
+
+func TestMatchAutoRegistration(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method != "action.get" {
+			t.Fatalf("Unexpected call to %s", method)
+		}
+		var p struct {
+			Filter map[string]int `json:"filter"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Filter["eventsource"] != 2 || p.Filter["status"] != 0 {
+			t.Errorf("Bad filter: %s", params)
+		}
+		return []interface{}{
+			map[string]interface{}{"actionid": "1", "name": "Linux", "eventsource": "2", "status": "0",
+				"filter": map[string]interface{}{"evaltype": "0", "conditions": []interface{}{
+					map[string]interface{}{"conditiontype": "24", "operator": "2", "value": "Linux"},
+				}}},
+			map[string]interface{}{"actionid": "2", "name": "Web servers", "eventsource": "2", "status": "0",
+				"filter": map[string]interface{}{"evaltype": "3", "formula": "A and not B", "conditions": []interface{}{
+					map[string]interface{}{"conditiontype": "22", "operator": "8", "value": "^web-\\d+$", "formulaid": "A"},
+					map[string]interface{}{"conditiontype": "24", "operator": "2", "value": "staging", "formulaid": "B"},
+				}}},
+			map[string]interface{}{"actionid": "3", "name": "Windows", "eventsource": "2", "status": "0",
+				"filter": map[string]interface{}{"evaltype": "0", "conditions": []interface{}{
+					map[string]interface{}{"conditiontype": "24", "operator": "2", "value": "Windows"},
+				}}},
+		}, nil
+	})
+
+	for _, c := range []struct {
+		metadata, hostname string
+		expected           []string
+	}{
+		{"Linux x86_64", "web-01", []string{"1", "2"}},
+		{"Linux staging", "web-01", []string{"1"}},
+		{"Windows", "db-01", []string{"3"}},
+		{"FreeBSD", "db-01", nil},
+	} {
+		actions, err := api.MatchAutoRegistration(c.metadata, c.hostname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, action := range actions {
+			ids = append(ids, action.ActionID)
+		}
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("%q %q: expected actions %v, got %v", c.metadata, c.hostname, c.expected, ids)
+		}
+	}
+}
@@ -28,32 +28,18 @@ func (api *API) ApplicationsGet(params Params) (res Applications, err error) {
 
 // ApplicationGetByID Gets application by Id only if there is exactly 1 matching application.
 func (api *API) ApplicationGetByID(id string) (res *Application, err error) {
-	apps, err := api.ApplicationsGet(Params{"applicationids": id})
-	if err != nil {
-		return
-	}
-
-	if len(apps) == 1 {
-		res = &apps[0]
-	} else {
-		e := ExpectedOneResult(len(apps))
-		err = &e
+	res = &Application{}
+	if err = api.GetOne("application.get", Params{"applicationids": id}, res); err != nil {
+		res = nil
 	}
 	return
 }
 
 // ApplicationGetByHostIDAndName Gets application by host Id and name only if there is exactly 1 matching application.
 func (api *API) ApplicationGetByHostIDAndName(hostID, name string) (res *Application, err error) {
-	apps, err := api.ApplicationsGet(Params{"hostids": hostID, "filter": map[string]string{"name": name}})
-	if err != nil {
-		return
-	}
-
-	if len(apps) == 1 {
-		res = &apps[0]
-	} else {
-		e := ExpectedOneResult(len(apps))
-		err = &e
+	res = &Application{}
+	if err = api.GetOne("application.get", Params{"hostids": hostID, "filter": map[string]string{"name": name}}, res); err != nil {
+		res = nil
 	}
 	return
 }
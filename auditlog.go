@@ -0,0 +1,242 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type (
+	// AuditAction action recorded by an audit log entry
+	// see "action" in https://www.zabbix.com/documentation/6.0/manual/api/reference/auditlog/object
+	AuditAction int
+
+	// AuditResourceType type of the resource of an audit log entry
+	// see "resourcetype" in https://www.zabbix.com/documentation/6.0/manual/api/reference/auditlog/object
+	AuditResourceType int
+)
+
+const (
+	// AuditAdd add
+	AuditAdd AuditAction = 0
+	// AuditUpdate update
+	AuditUpdate AuditAction = 1
+	// AuditDelete delete
+	AuditDelete AuditAction = 2
+	// AuditLogout logout
+	AuditLogout AuditAction = 4
+	// AuditExecute execute
+	AuditExecute AuditAction = 7
+	// AuditLogin login
+	AuditLogin AuditAction = 8
+	// AuditFailedLogin failed login
+	AuditFailedLogin AuditAction = 9
+	// AuditHistoryClear history clear
+	AuditHistoryClear AuditAction = 10
+	// AuditConfigRefresh configuration refresh
+	AuditConfigRefresh AuditAction = 11
+)
+
+const (
+	// AuditUser user
+	AuditUser AuditResourceType = 0
+	// AuditMediaType media type
+	AuditMediaType AuditResourceType = 3
+	// AuditHost host
+	AuditHost AuditResourceType = 4
+	// AuditActionResource action
+	AuditActionResource AuditResourceType = 5
+	// AuditGraph graph
+	AuditGraph AuditResourceType = 6
+	// AuditUserGroup user group
+	AuditUserGroup AuditResourceType = 11
+	// AuditTrigger trigger
+	AuditTrigger AuditResourceType = 13
+	// AuditHostGroup host group
+	AuditHostGroup AuditResourceType = 14
+	// AuditItem item
+	AuditItem AuditResourceType = 15
+	// AuditValueMap value map
+	AuditValueMap AuditResourceType = 17
+	// AuditWebScenario web scenario
+	AuditWebScenario AuditResourceType = 22
+	// AuditDiscoveryRule discovery rule
+	AuditDiscoveryRule AuditResourceType = 23
+	// AuditScript script
+	AuditScript AuditResourceType = 25
+	// AuditProxy proxy
+	AuditProxy AuditResourceType = 26
+	// AuditMaintenance maintenance
+	AuditMaintenance AuditResourceType = 27
+	// AuditMacro macro
+	AuditMacro AuditResourceType = 29
+	// AuditTemplate template
+	AuditTemplate AuditResourceType = 30
+	// AuditDashboard dashboard
+	AuditDashboard AuditResourceType = 33
+	// AuditTemplateDashboard template dashboard
+	AuditTemplateDashboard AuditResourceType = 43
+)
+
+// AuditChange is the change of one field recorded in the details of an audit log entry
+type AuditChange struct {
+	// Action is "add", "update", "delete", "attach" or "detach"
+	Action string
+	New    string
+	Old    string
+}
+
+// AuditLogEntry represent Zabbix audit log object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/auditlog/object
+type AuditLogEntry struct {
+	AuditID      string            `json:"auditid"`
+	UserID       string            `json:"userid"`
+	Username     string            `json:"username"`
+	Clock        string            `json:"clock"`
+	Action       AuditAction       `json:"action,string"`
+	ResourceType AuditResourceType `json:"resourcetype,string"`
+	ResourceID   string            `json:"resourceid"`
+	ResourceName string            `json:"resourcename"`
+	RecordSetID  string            `json:"recordsetid"`
+	IP           string            `json:"ip"`
+
+	// RawDetails is the JSON encoded list of changes
+	RawDetails string `json:"details"`
+	// Details are the changes by field path, like "host.name"
+	Details map[string]AuditChange `json:"-"`
+}
+
+// AuditLog is an array of AuditLogEntry
+type AuditLog []AuditLogEntry
+
+// Time returns the time of the entry
+func (e AuditLogEntry) Time() time.Time {
+	return unixTime(e.Clock)
+}
+
+// AuditLogOptions typed parameters for auditlog.get
+type AuditLogOptions struct {
+	GetOptions
+	UserIDs       []string
+	Actions       []AuditAction
+	ResourceTypes []AuditResourceType
+	// From and Till bound the entries time when set
+	From time.Time
+	Till time.Time
+}
+
+func (o AuditLogOptions) params() Params {
+	params := o.GetOptions.params()
+	params.setIDs("userids", o.UserIDs)
+	if len(o.Actions) > 0 {
+		params.setFilter("action", o.Actions)
+	}
+	if len(o.ResourceTypes) > 0 {
+		params.setFilter("resourcetype", o.ResourceTypes)
+	}
+	if !o.From.IsZero() {
+		params["time_from"] = o.From.Unix()
+	}
+	if !o.Till.IsZero() {
+		params["time_till"] = o.Till.Unix()
+	}
+	return params
+}
+
+// AuditLogGet Wrapper for auditlog.get, Zabbix 5.4+
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/auditlog/get
+func (api *API) AuditLogGet(opts AuditLogOptions) (res AuditLog, err error) {
+	params := opts.params()
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	if err = api.CallWithErrorParse("auditlog.get", params, &res); err != nil {
+		return
+	}
+	for i := range res {
+		res[i].Details = decodeAuditDetails(res[i].RawDetails)
+	}
+	err = opts.truncated(len(res))
+	return
+}
+
+// decodeAuditDetails decodes details like {"host.name":["update","new","old"]}, nil if there are none
+func decodeAuditDetails(raw string) map[string]AuditChange {
+	var details map[string][]string
+	if raw == "" || json.Unmarshal([]byte(raw), &details) != nil || len(details) == 0 {
+		return nil
+	}
+
+	res := make(map[string]AuditChange, len(details))
+	for path, change := range details {
+		var c AuditChange
+		if len(change) > 0 {
+			c.Action = change[0]
+		}
+		if len(change) > 1 {
+			c.New = change[1]
+		}
+		if len(change) > 2 {
+			c.Old = change[2]
+		}
+		res[path] = c
+	}
+	return res
+}
+
+// auditLogPage is the number of entries fetched per call by AuditLogStream
+const auditLogPage = 1000
+
+// AuditLogStream Calls fn with the audit log entries from sinceClock (unix time, inclusive)
+// onwards, oldest first, polling for new ones every poll until ctx is done or fn fails.
+//
+// Delivery is at least once: entries of the last second seen are fetched again and
+// skipped by id, but when resuming from a saved clock after a restart, entries of
+// that second are delivered again. Callers needing exactly once must dedup on AuditID.
+func (api *API) AuditLogStream(ctx context.Context, sinceClock int64, poll time.Duration, fn func(AuditLogEntry) error) error {
+	cursor := sinceClock
+	// ids already delivered with clock == cursor
+	seen := map[string]bool{}
+	for {
+		// auditlog.get has no offset, the page grows by the entries of the cursor
+		// second already delivered so that more than a page of them can be passed
+		limit := len(seen) + auditLogPage
+		entries, err := api.AuditLogGet(AuditLogOptions{
+			GetOptions: GetOptions{SortFields: []string{"clock", "auditid"}, SortOrder: SortAsc, Limit: limit},
+			From:       time.Unix(cursor, 0),
+		})
+		if err != nil {
+			return err
+		}
+
+		delivered := 0
+		for _, e := range entries {
+			if seen[e.AuditID] {
+				continue
+			}
+			if err = fn(e); err != nil {
+				return err
+			}
+			delivered++
+			if clock := e.Time().Unix(); clock > cursor {
+				cursor = clock
+				seen = map[string]bool{}
+			}
+			seen[e.AuditID] = true
+		}
+
+		// a full page may leave entries behind, fetch them right away unless
+		// it brought nothing new, which would only repeat the same query
+		wait := poll
+		if len(entries) == limit && delivered > 0 {
+			wait = 0
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package zabbix_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestAuditLogGet(t *testing.T) {
+	var sent map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return []interface{}{map[string]string{
+			"auditid":      "cksstgfam0001yhdcc41y20q2",
+			"userid":       "1",
+			"username":     "Admin",
+			"clock":        "1629975715",
+			"ip":           "127.0.0.1",
+			"action":       "1",
+			"resourcetype": "4",
+			"resourceid":   "10084",
+			"resourcename": "Zabbix server",
+			"recordsetid":  "cksstgfal0000yhdcso67ondl",
+			"details":      `{"host.name":["update","Main server","Zabbix server"],"host.macros[5]":["add"]}`,
+		}}, nil
+	})
+
+	from := time.Unix(1629970000, 0)
+	entries, err := api.AuditLogGet(zapi.AuditLogOptions{
+		Actions:       []zapi.AuditAction{zapi.AuditUpdate},
+		ResourceTypes: []zapi.AuditResourceType{zapi.AuditHost},
+		From:          from,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent["time_from"] != float64(from.Unix()) {
+		t.Errorf("Bad params: %#v", sent)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+
+	e := entries[0]
+	if e.Action != zapi.AuditUpdate || e.ResourceType != zapi.AuditHost || e.Time().Unix() != 1629975715 {
+		t.Errorf("Bad entry: %#v", e)
+	}
+	expected := map[string]zapi.AuditChange{
+		"host.name":      {Action: "update", New: "Main server", Old: "Zabbix server"},
+		"host.macros[5]": {Action: "add"},
+	}
+	if !reflect.DeepEqual(e.Details, expected) {
+		t.Errorf("Bad details: %#v", e.Details)
+	}
+}
+
+func TestAuditActions(t *testing.T) {
+	// values of Zabbix 5.4+
+	expected := map[zapi.AuditAction]int{
+		zapi.AuditAdd: 0, zapi.AuditUpdate: 1, zapi.AuditDelete: 2, zapi.AuditLogout: 4,
+		zapi.AuditExecute: 7, zapi.AuditLogin: 8, zapi.AuditFailedLogin: 9,
+		zapi.AuditHistoryClear: 10, zapi.AuditConfigRefresh: 11,
+	}
+	for action, value := range expected {
+		if int(action) != value {
+			t.Errorf("Expected action %d, got %d", value, action)
+		}
+	}
+
+	var sent map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return []map[string]string{{"auditid": "1", "clock": "100", "action": "8"}}, nil
+	})
+	entries, err := api.AuditLogGet(zapi.AuditLogOptions{Actions: []zapi.AuditAction{zapi.AuditLogin, zapi.AuditFailedLogin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sent["filter"], map[string]interface{}{"action": []interface{}{float64(8), float64(9)}}) {
+		t.Errorf("Bad action filter: %#v", sent)
+	}
+	if len(entries) != 1 || entries[0].Action != zapi.AuditLogin {
+		t.Errorf("Bad entries: %#v", entries)
+	}
+}
+
+func TestAuditLogStream(t *testing.T) {
+	all := []map[string]string{
+		{"auditid": "a1", "clock": "100", "action": "0", "resourcetype": "4"},
+		{"auditid": "a2", "clock": "105", "action": "1", "resourcetype": "4"},
+		{"auditid": "a3", "clock": "105", "action": "1", "resourcetype": "15"},
+		{"auditid": "a4", "clock": "110", "action": "2", "resourcetype": "15"},
+	}
+	// entries become visible over the polls, a3 lands in the second already seen
+	visible := []int{2, 3, 4}
+	poll := 0
+	froms := []int64{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var p struct {
+			From int64 `json:"time_from"`
+		}
+		json.Unmarshal(params, &p)
+		froms = append(froms, p.From)
+		n := visible[len(visible)-1]
+		if poll < len(visible) {
+			n = visible[poll]
+		}
+		poll++
+		res := []map[string]string{}
+		for _, e := range all[:n] {
+			if clock, _ := strconv.ParseInt(e["clock"], 10, 64); clock >= p.From {
+				res = append(res, e)
+			}
+		}
+		return res, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got := []string{}
+	err := api.AuditLogStream(ctx, 90, time.Millisecond, func(e zapi.AuditLogEntry) error {
+		got = append(got, e.AuditID)
+		if len(got) == len(all) {
+			cancel()
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"a1", "a2", "a3", "a4"}) {
+		t.Errorf("Bad entries streamed: %v", got)
+	}
+	if froms[0] != 90 || froms[1] != 105 || froms[2] != 105 {
+		t.Errorf("Cursor not advanced: %v", froms)
+	}
+}
+
+func TestAuditLogStreamSameSecond(t *testing.T) {
+	// more than a page of entries in the same second, then a later one
+	all := []map[string]string{}
+	for i := 0; i < 1500; i++ {
+		all = append(all, map[string]string{"auditid": fmt.Sprintf("a%04d", i), "clock": "100", "action": "1", "resourcetype": "4"})
+	}
+	all = append(all, map[string]string{"auditid": "b0000", "clock": "101", "action": "1", "resourcetype": "4"})
+
+	calls := 0
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var p struct {
+			From  int64 `json:"time_from"`
+			Limit int   `json:"limit"`
+		}
+		json.Unmarshal(params, &p)
+		calls++
+		res := []map[string]string{}
+		for _, e := range all {
+			if clock, _ := strconv.ParseInt(e["clock"], 10, 64); clock >= p.From && len(res) < p.Limit {
+				res = append(res, e)
+			}
+		}
+		return res, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got := map[string]bool{}
+	err := api.AuditLogStream(ctx, 100, time.Millisecond, func(e zapi.AuditLogEntry) error {
+		if got[e.AuditID] {
+			t.Errorf("Entry %s delivered twice", e.AuditID)
+		}
+		got[e.AuditID] = true
+		if len(got) == len(all) {
+			cancel()
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v after %d entries", err, len(got))
+	}
+	if calls > 10 {
+		t.Errorf("Too many calls: %d", calls)
+	}
+}
+
+func TestAuditLogStreamStuckPage(t *testing.T) {
+	// a server ignoring the grown limit keeps returning the same full page
+	page := []map[string]string{}
+	for i := 0; i < 1000; i++ {
+		page = append(page, map[string]string{"auditid": fmt.Sprintf("a%04d", i), "clock": "100"})
+	}
+	calls := 0
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		calls++
+		return page, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	api.AuditLogStream(ctx, 100, 40*time.Millisecond, func(e zapi.AuditLogEntry) error { return nil })
+	if calls > 4 {
+		t.Errorf("Busy loop on a page without new entries: %d calls", calls)
+	}
+}
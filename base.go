@@ -2,14 +2,21 @@ package zabbix
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math/rand"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type (
@@ -52,6 +59,93 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("%d (%s): %s", e.Code, e.Message, e.Data)
 }
 
+// isAuthError reports whether the error is the server refusing an unauthenticated call
+func (e *Error) isAuthError() bool {
+	if e.Code == -32602 {
+		return true
+	}
+	text := strings.ToLower(e.Message + " " + e.Data)
+	for _, s := range []string{"auth", "session", "permission", "login"} {
+		if strings.Contains(text, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// VersionAuthError is returned by Version when the server requires authentication for it
+type VersionAuthError struct {
+	Err *Error
+}
+
+func (e *VersionAuthError) Error() string {
+	return fmt.Sprintf("%s (server requires authentication for apiinfo.version, call Login first)", e.Err)
+}
+
+// DiscoveredError is returned when deleting objects created by low level discovery,
+// those are removed through their discovery rule
+type DiscoveredError struct {
+	Object string
+	Names  []string
+}
+
+func (e *DiscoveredError) Error() string {
+	return fmt.Sprintf("Cannot delete discovered %s %s, remove them through their discovery rule.", e.Object, strings.Join(e.Names, ", "))
+}
+
+// NotFoundError lists the names which could not be resolved to an object
+type NotFoundError struct {
+	Object string
+	Names  []string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("Cannot find %s %s.", e.Object, strings.Join(e.Names, ", "))
+}
+
+// checkResolved returns a NotFoundError for names missing in resolved
+func checkResolved(object string, names []string, resolved map[string]string) error {
+	missing := []string{}
+	for _, name := range names {
+		if _, ok := resolved[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return &NotFoundError{object, missing}
+	}
+	return nil
+}
+
+// ErrReadOnly is returned for methods modifying Zabbix when Config.ReadOnly is set
+var ErrReadOnly = errors.New("zabbix: mutating call refused in read only mode")
+
+// readSuffixes are the method suffixes of calls not modifying Zabbix, allowed in read only mode
+var readSuffixes = []string{".get", ".getsli", ".export", ".checkauthentication", ".importcompare"}
+
+// readMethods are the other methods not modifying Zabbix configuration or data,
+// method names are matched lower cased as Zabbix ignores their case
+var readMethods = map[string]bool{
+	"apiinfo.version": true,
+	"user.login":      true,
+	"user.logout":     true,
+}
+
+// isMutating reports whether the API method may modify Zabbix,
+// any method not known to only read is
+func isMutating(method string) bool {
+	method = strings.ToLower(method)
+	if readMethods[method] {
+		return false
+	}
+	for _, suffix := range readSuffixes {
+		if strings.HasSuffix(method, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
 // ExpectedOneResult use to generate error when you expect one result
 type ExpectedOneResult int
 
@@ -69,16 +163,83 @@ func (e *ExpectedMore) Error() string {
 	return fmt.Sprintf("Expected %d, got %d.", e.Expected, e.Got)
 }
 
+// resultIDs returns the ids listed under key in the result of a create or delete call,
+// as an array or an object depending on the version, an error if the result has none
+func resultIDs(response Response, key string) (ids []string, err error) {
+	result, ok := response.Result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected result without %s: %v", key, response.Result)
+	}
+	var raw []interface{}
+	switch v := result[key].(type) {
+	case []interface{}:
+		raw = v
+	case map[string]interface{}:
+		// objects are keyed by the index of the created object
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			a, errA := strconv.Atoi(keys[i])
+			b, errB := strconv.Atoi(keys[j])
+			if errA != nil || errB != nil {
+				return keys[i] < keys[j]
+			}
+			return a < b
+		})
+		for _, k := range keys {
+			raw = append(raw, v[k])
+		}
+	default:
+		return nil, fmt.Errorf("unexpected result without %s: %v", key, response.Result)
+	}
+	for _, id := range raw {
+		switch v := id.(type) {
+		case string:
+			ids = append(ids, v)
+		case float64:
+			ids = append(ids, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			return nil, fmt.Errorf("unexpected id in %s: %v", key, id)
+		}
+	}
+	return
+}
+
+// GetOne Calls the get method and parses the only result into result, a pointer to an object.
+// ExpectedOneResult is returned when there are none or several results.
+// All fields are returned unless "output" is set in params.
+func (api *API) GetOne(method string, params Params, result interface{}) (err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	var res []json.RawMessage
+	if err = api.CallWithErrorParse(method, params, &res); err != nil {
+		return
+	}
+	if len(res) != 1 {
+		e := ExpectedOneResult(len(res))
+		return &e
+	}
+	return json.Unmarshal(res[0], result)
+}
+
 // API use to store connection information
 type API struct {
 	Auth      string      // auth token, filled by Login()
+	Session   *Session    // session details, filled by LoginDetailed()
 	Logger    *log.Logger // request/response logger, nil by default
 	UserAgent string
 	url       string
 	c         http.Client
 	id        int32
 	ex        sync.Mutex
-	Config    Config
+	busy      int32
+	version   int32 // detected server version, see ServerVersion
+	// valueTypes caches item value types by item id, see ItemValueType
+	valueTypes sync.Map
+	Config     Config
 }
 
 type Config struct {
@@ -86,9 +247,95 @@ type Config struct {
 	TlsNoVerify bool
 	Log         *log.Logger
 	Serialize   bool
-	Version     int
+	// Version of the server as major*10000+minor*100+patch, detected when zero
+	Version int
+
+	// BaseContext is the parent context of every request when set,
+	// cancelling it aborts all in-flight calls.
+	BaseContext context.Context
+
+	// Transport tuning, zero values use the defaults below
+	MaxIdleConns          int
+	IdleConnTimeout       time.Duration
+	DisableKeepAlives     bool
+	ResponseHeaderTimeout time.Duration
+
+	// DetectConcurrentMisuse panics when calls that modify the API
+	// structure (Login, Version) overlap. Meant for development only.
+	DetectConcurrentMisuse bool
+
+	// ReadOnly refuses every method modifying Zabbix with ErrReadOnly,
+	// before anything is sent.
+	ReadOnly bool
+
+	// RetryCodes are the JSON-RPC error codes on which read calls are retried,
+	// nil retries -32500 (transient errors under database contention), empty disables.
+	// Calls modifying Zabbix are never retried.
+	RetryCodes []int
+	// RetryDelay is the wait before the first retry, doubled on each attempt
+	RetryDelay time.Duration
+
+	// Timeout bounds each HTTP request, 30s when zero, negative disables it
+	Timeout time.Duration
+
+	// Retry retries read calls failing on network errors or HTTP 5xx, disabled by default
+	Retry RetryConfig
+
+	// EnableCompression asks for compressed responses, and compresses requests
+	// once the server advertises gzip support
+	EnableCompression bool
+	// AcceptedEncodings are sent in Accept-Encoding with EnableCompression,
+	// gzip, deflate and identity when nil
+	AcceptedEncodings []string
+}
+
+// RetryConfig tells how calls failing on network errors or HTTP 5xx status codes are retried.
+// Responses holding a JSON-RPC error are never retried this way, see Config.RetryCodes.
+// Like those, calls modifying Zabbix are never retried, as they may have been applied.
+type RetryConfig struct {
+	// MaxAttempts is the number of attempts including the first, retries are disabled below 2
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry, doubled on each attempt, 100ms when zero
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between attempts when set
+	MaxDelay time.Duration
+	// Jitter is the fraction, 0 to 1, of each wait randomly removed to spread retries
+	Jitter float64
 }
 
+// delay returns the wait before attempt n+1
+func (c RetryConfig) delay(n int) time.Duration {
+	d := c.BaseDelay
+	if d == 0 {
+		d = defaultRetryDelay
+	}
+	for i := 1; i < n && (c.MaxDelay == 0 || d < c.MaxDelay); i++ {
+		d *= 2
+	}
+	if c.MaxDelay > 0 && d > c.MaxDelay {
+		d = c.MaxDelay
+	}
+	if c.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * c.Jitter * float64(d))
+	}
+	return d
+}
+
+// chunkSize is the number of objects sent per call by bulk helpers
+const chunkSize = 100
+
+const (
+	defaultMaxIdleConns    = 100
+	defaultIdleConnTimeout = 90 * time.Second
+	defaultTimeout         = 30 * time.Second
+	defaultReadRetries     = 2
+	defaultRetryDelay      = 100 * time.Millisecond
+)
+
+var defaultRetryCodes = []int{-32500}
+
+const concurrentMisuse = "zabbix: API used concurrently; use a separate API per goroutine or set Config.Serialize"
+
 // NewAPI Creates new API access object.
 // Typical URL is http://host/api_jsonrpc.php or http://host/zabbix/api_jsonrpc.php.
 // It also may contain HTTP basic auth username and password like
@@ -96,24 +343,50 @@ type Config struct {
 func NewAPI(c Config) (api *API) {
 	api = &API{
 		url:       c.Url,
-		c:         http.Client{},
 		UserAgent: "github.com/tpretz/go-zabbix-api",
 		Logger:    c.Log,
 		Config:    c,
 	}
 
+	tr := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		TLSHandshakeTimeout:   10 * time.Second,
+		MaxIdleConns:          c.MaxIdleConns,
+		IdleConnTimeout:       c.IdleConnTimeout,
+		DisableKeepAlives:     c.DisableKeepAlives,
+		ResponseHeaderTimeout: c.ResponseHeaderTimeout,
+	}
+	if tr.MaxIdleConns == 0 {
+		tr.MaxIdleConns = defaultMaxIdleConns
+	}
+	if tr.IdleConnTimeout == 0 {
+		tr.IdleConnTimeout = defaultIdleConnTimeout
+	}
+
 	if c.TlsNoVerify {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		}
-		api.c = http.Client{
-			Transport: tr,
+		tr.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: true,
 		}
 		api.printf("TLS running in insecure mode, do not use this configuration in production")
 	}
 
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	} else if timeout < 0 {
+		timeout = 0
+	}
+	api.c = http.Client{
+		Transport: tr,
+		Timeout:   timeout,
+	}
+	if c.EnableCompression {
+		encodings := c.AcceptedEncodings
+		if encodings == nil {
+			encodings = defaultAcceptedEncodings
+		}
+		api.c.Transport = &compressionTransport{transport: tr, acceptedEncodings: encodings}
+	}
 	return
 }
 
@@ -128,20 +401,109 @@ func (api *API) printf(format string, v ...interface{}) {
 	}
 }
 
+// guard marks the start of a call modifying the API structure.
+// The returned func must be called when the call is done.
+func (api *API) guard() func() {
+	if !api.Config.DetectConcurrentMisuse {
+		return func() {}
+	}
+	if !atomic.CompareAndSwapInt32(&api.busy, 0, 1) {
+		api.printf(concurrentMisuse)
+		panic(concurrentMisuse)
+	}
+	return func() { atomic.StoreInt32(&api.busy, 0) }
+}
+
 func (api *API) callBytes(method string, params interface{}) (b []byte, err error) {
+	return api.callBytesAuth(method, params, api.Auth)
+}
+
+// callBytesAuth is callBytes using auth instead of api.Auth
+func (api *API) callBytesAuth(method string, params interface{}, auth string) (b []byte, err error) {
+	if api.Config.ReadOnly && isMutating(method) {
+		api.printf("Refused %s in read only mode", method)
+		return nil, ErrReadOnly
+	}
+
 	id := atomic.AddInt32(&api.id, 1)
-	jsonobj := request{"2.0", method, params, api.Auth, id}
-	b, err = json.Marshal(jsonobj)
+	jsonobj := request{"2.0", method, params, auth, id}
+	body, err := json.Marshal(jsonobj)
 	if err != nil {
 		return
 	}
-	api.printf("Request (POST): %s", b)
 
-	req, err := http.NewRequest("POST", api.url, bytes.NewReader(b))
+	retries, delay := 0, api.Config.RetryDelay
+	attempts := 1
+	if !isMutating(method) {
+		retries = defaultReadRetries
+		attempts = api.Config.Retry.MaxAttempts
+	}
+	if delay == 0 {
+		delay = defaultRetryDelay
+	}
+	for attempt, transportAttempt := 1, 1; ; {
+		var status int
+		b, status, err = api.post(body)
+		if reason := api.transportFailure(status, b, err); reason != "" {
+			if transportAttempt >= attempts {
+				if err == nil {
+					err = fmt.Errorf("zabbix: %s", reason)
+				}
+				return
+			}
+			d := api.Config.Retry.delay(transportAttempt)
+			transportAttempt++
+			api.printf("Retrying %s in %s after %s, attempt %d of %d", method, d, reason, transportAttempt, attempts)
+			if err = api.sleep(d); err != nil {
+				return
+			}
+			continue
+		}
+		if err != nil || attempt > retries || !api.retryable(b) {
+			return
+		}
+		attempt++
+		api.printf("Retrying %s in %s, attempt %d", method, delay, attempt)
+		if err = api.sleep(delay); err != nil {
+			return
+		}
+		delay *= 2
+	}
+}
+
+// transportFailure describes a network error or an HTTP 5xx response not holding
+// a JSON-RPC response, empty for other outcomes including a done Config.BaseContext
+func (api *API) transportFailure(status int, b []byte, err error) string {
+	if err != nil {
+		if api.Config.BaseContext != nil && api.Config.BaseContext.Err() != nil {
+			return ""
+		}
+		return err.Error()
+	}
+	if status < 500 {
+		return ""
+	}
+	var response struct {
+		Error *Error `json:"error"`
+	}
+	if json.Unmarshal(b, &response) == nil && response.Error != nil {
+		return ""
+	}
+	return fmt.Sprintf("HTTP status %d", status)
+}
+
+// post sends a request body and returns the response status and body
+func (api *API) post(body []byte) (b []byte, status int, err error) {
+	api.printf("Request (POST): %s", body)
+
+	req, err := http.NewRequest("POST", api.url, bytes.NewReader(body))
 	if err != nil {
 		return
 	}
-	req.ContentLength = int64(len(b))
+	if api.Config.BaseContext != nil {
+		req = req.WithContext(api.Config.BaseContext)
+	}
+	req.ContentLength = int64(len(body))
 	req.Header.Add("Content-Type", "application/json-rpc")
 	req.Header.Add("User-Agent", api.UserAgent)
 
@@ -157,11 +519,49 @@ func (api *API) callBytes(method string, params interface{}) (b []byte, err erro
 	}
 	defer res.Body.Close()
 
+	status = res.StatusCode
 	b, err = ioutil.ReadAll(res.Body)
 	api.printf("Response (%d): %s", res.StatusCode, b)
 	return
 }
 
+// retryable reports whether the response is an error listed in Config.RetryCodes,
+// permission errors share their code with transient ones but are never retried.
+func (api *API) retryable(b []byte) bool {
+	var response struct {
+		Error *Error `json:"error"`
+	}
+	if json.Unmarshal(b, &response) != nil || response.Error == nil || response.Error.isAuthError() {
+		return false
+	}
+	codes := api.Config.RetryCodes
+	if codes == nil {
+		codes = defaultRetryCodes
+	}
+	for _, code := range codes {
+		if response.Error.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// sleep waits for d, returning early with the error of Config.BaseContext when it is done
+func (api *API) sleep(d time.Duration) error {
+	if api.Config.BaseContext == nil {
+		time.Sleep(d)
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-api.Config.BaseContext.Done():
+		return api.Config.BaseContext.Err()
+	}
+}
+
 // Call Calls specified API method. Uses api.Auth if not empty.
 // err is something network or marshaling related. Caller should inspect response.Error to get API error.
 func (api *API) Call(method string, params interface{}) (response Response, err error) {
@@ -184,9 +584,14 @@ func (api *API) CallWithError(method string, params interface{}) (response Respo
 // CallWithErrorParse Calls specified API method.
 // Parse the response of the api in the result variable.
 func (api *API) CallWithErrorParse(method string, params interface{}, result interface{}) (err error) {
+	return api.callWithErrorParseAuth(method, params, result, api.Auth)
+}
+
+// callWithErrorParseAuth is CallWithErrorParse using auth instead of api.Auth
+func (api *API) callWithErrorParseAuth(method string, params interface{}, result interface{}, auth string) (err error) {
 	var rawResult RawResponse
 
-	response, err := api.callBytes(method, params)
+	response, err := api.callBytesAuth(method, params, auth)
 	if err != nil {
 		return
 	}
@@ -201,23 +606,181 @@ func (api *API) CallWithErrorParse(method string, params interface{}, result int
 	return
 }
 
+// ctxErr returns the error of Config.BaseContext, nil if unset or not done.
+// Bulk helpers check it between calls.
+func (api *API) ctxErr() error {
+	if api.Config.BaseContext == nil {
+		return nil
+	}
+	return api.Config.BaseContext.Err()
+}
+
+// chunked calls f with the ids split in chunks of chunkSize, stopping at the
+// first error or when Config.BaseContext is done
+func (api *API) chunked(ids []string, f func([]string) error) error {
+	for start := 0; start < len(ids); start += chunkSize {
+		if err := api.ctxErr(); err != nil {
+			return err
+		}
+		end := start + chunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if err := f(ids[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateFields calls an update method sending only fields and the object id
+func (api *API) updateFields(method, idField, id string, fields map[string]interface{}) (err error) {
+	params := make(Params, len(fields)+1)
+	for k, v := range fields {
+		params[k] = v
+	}
+	params[idField] = id
+	_, err = api.CallWithError(method, params)
+	return
+}
+
 // Login Calls "user.login" API method and fills api.Auth field.
 // This method modifies API structure and should not be called concurrently with other methods.
 func (api *API) Login(user, password string) (auth string, err error) {
-	params := map[string]string{"user": user, "password": password}
-	response, err := api.CallWithError("user.login", params)
+	defer api.guard()()
+
+	var response Response
+	err = api.login(user, password, Params{}, func(params Params) (err error) {
+		response, err = api.CallWithError("user.login", params)
+		return
+	})
 	if err != nil {
 		return
 	}
 
-	auth = response.Result.(string)
+	auth, ok := response.Result.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected user.login result: %v", response.Result)
+	}
 	api.Auth = auth
 	return
 }
 
+// login calls call with the user.login params, the user name being "username" since
+// Zabbix 5.4 and "user" before. When the version is unknown both are tried.
+func (api *API) login(user, password string, params Params, call func(Params) error) (err error) {
+	keys := []string{"username", "user"}
+	if v, err := api.ServerVersion(); err == nil {
+		if v < 50400 {
+			keys = keys[1:]
+		} else {
+			keys = keys[:1]
+		}
+	}
+
+	params["password"] = password
+	for _, key := range keys {
+		delete(params, "username")
+		delete(params, "user")
+		params[key] = user
+		err = call(params)
+		if e, ok := err.(*Error); !ok || !strings.Contains(e.Data, "unexpected parameter") {
+			return
+		}
+	}
+	return
+}
+
+// LoginWithToken Uses token, an API token created in the frontend, as api.Auth after checking it
+// is accepted, or calls Login when token is empty. The server version is detected on success.
+// This method modifies API structure and should not be called concurrently with other methods.
+func (api *API) LoginWithToken(user, password, token string) (auth string, err error) {
+	if token == "" {
+		auth, err = api.Login(user, password)
+	} else {
+		auth, err = api.useToken(token)
+	}
+	if err != nil {
+		return
+	}
+	_, err = api.ServerVersion()
+	return
+}
+
+// useToken sets api.Auth to token when a call authenticated with it succeeds
+func (api *API) useToken(token string) (auth string, err error) {
+	defer api.guard()()
+
+	var users []User
+	if err = api.callWithErrorParseAuth("user.get", Params{"output": []string{"userid"}, "limit": 1}, &users, token); err != nil {
+		return
+	}
+	api.Auth = token
+	return token, nil
+}
+
+// Session holds the details returned by "user.login" when asked for user data
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/user/login
+type Session struct {
+	Token      string `json:"sessionid"`
+	UserID     string `json:"userid"`
+	Username   string `json:"username,omitempty"`
+	Alias      string `json:"alias,omitempty"` // username before Zabbix 5.4
+	Name       string `json:"name,omitempty"`
+	Surname    string `json:"surname,omitempty"`
+	RoleID     string `json:"roleid,omitempty"`
+	AutoLogout string `json:"autologout,omitempty"`
+	// Expires is when the session ends if left unused, zero when it does not
+	Expires time.Time `json:"-"`
+}
+
+// LoginDetailed Calls "user.login" API method asking for user data, fills api.Auth and api.Session fields.
+// This method modifies API structure and should not be called concurrently with other methods.
+func (api *API) LoginDetailed(user, password string) (session *Session, err error) {
+	defer api.guard()()
+
+	session = &Session{}
+	err = api.login(user, password, Params{"userData": true}, func(params Params) error {
+		return api.CallWithErrorParse("user.login", params, session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if timeout, err := parseDuration(session.AutoLogout); err == nil && timeout > 0 {
+		session.Expires = time.Now().Add(timeout)
+	}
+
+	api.Auth = session.Token
+	api.Session = session
+	return
+}
+
+// Logout Calls "user.logout" API method, then clears api.Auth, api.Session and the detected
+// server version. Does nothing when not logged in, and clears them too when the session was
+// already gone. This method modifies API structure and should not be called concurrently with other methods.
+func (api *API) Logout() (err error) {
+	defer api.guard()()
+
+	if api.Auth == "" {
+		return nil
+	}
+	var ok bool
+	err = api.CallWithErrorParse("user.logout", []string{}, &ok)
+	if e, isAPIError := err.(*Error); err != nil && (!isAPIError || !e.isAuthError()) {
+		return
+	}
+
+	api.Auth = ""
+	api.Session = nil
+	atomic.StoreInt32(&api.version, 0)
+	return nil
+}
+
 // Version Calls "APIInfo.version" API method.
 // This method temporary modifies API structure and should not be called concurrently with other methods.
 func (api *API) Version() (v string, err error) {
+	defer api.guard()()
+
 	// temporary remove auth for this method to succeed
 	// https://www.zabbix.com/documentation/2.2/manual/appendix/api/apiinfo/version
 	auth := api.Auth
@@ -225,14 +788,22 @@ func (api *API) Version() (v string, err error) {
 	response, err := api.CallWithError("APIInfo.version", Params{})
 	api.Auth = auth
 
-	// despite what documentation says, Zabbix 2.2 requires auth, so we try again
-	if e, ok := err.(*Error); ok && e.Code == -32602 {
+	// despite what documentation says, Zabbix 2.2 requires auth, so we try again,
+	// hardened setups refuse it with other authentication errors too
+	if e, ok := err.(*Error); ok && e.isAuthError() {
+		if api.Auth == "" {
+			err = &VersionAuthError{e}
+			return
+		}
 		response, err = api.CallWithError("APIInfo.version", Params{})
 	}
 	if err != nil {
 		return
 	}
 
-	v = response.Result.(string)
+	v, ok := response.Result.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected APIInfo.version result: %v", response.Result)
+	}
 	return
 }
@@ -1,11 +1,18 @@
 package zabbix_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"math/rand"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -67,6 +74,30 @@ func getAPI(t *testing.T) *zapi.API {
 	return _api
 }
 
+// mockHandler answers a single JSON-RPC call made against a mock server
+type mockHandler func(method string, params json.RawMessage) (interface{}, *zapi.Error)
+
+// getMockAPI returns an API talking to a local server answering with h
+func getMockAPI(t *testing.T, c zapi.Config, h mockHandler) *zapi.API {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			ID     int32           `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		res, e := h(req.Method, req.Params)
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "result": res, "error": e, "id": req.ID})
+	}))
+	t.Cleanup(srv.Close)
+
+	c.Url = srv.URL
+	return zapi.NewAPI(c)
+}
+
 func TestBadCalls(t *testing.T) {
 	api := getAPI(t)
 	res, err := api.Call("", nil)
@@ -89,3 +120,458 @@ func TestVersion(t *testing.T) {
 		t.Errorf("Unexpected version: %s", v)
 	}
 }
+
+func TestConcurrentMisuse(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	api := getMockAPI(t, zapi.Config{DetectConcurrentMisuse: true}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method == "user.login" {
+			close(entered)
+			<-release
+		}
+		return "token", nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		api.Login("user", "password")
+	}()
+	<-entered
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected concurrent use to be detected")
+			}
+		}()
+		api.Version()
+	}()
+	close(release)
+	<-done
+}
+
+func TestBaseContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	entered, release := make(chan struct{}), make(chan struct{})
+	api := getMockAPI(t, zapi.Config{BaseContext: ctx}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		close(entered)
+		<-release
+		return nil, nil
+	})
+	defer close(release)
+
+	errs := make(chan error)
+	go func() {
+		_, err := api.HostsGet(zapi.Params{})
+		errs <- err
+	}()
+	<-entered
+	cancel()
+
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("Expected in-flight call to fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("In-flight call was not aborted")
+	}
+}
+
+func TestVersionAuthRequired(t *testing.T) {
+	calls := 0
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		calls++
+		if calls%2 == 1 {
+			return nil, &zapi.Error{Code: -32500, Message: "Application error.", Data: "No permissions to call \"apiinfo.version\"."}
+		}
+		return "7.0.0", nil
+	})
+
+	_, err := api.Version()
+	if _, ok := err.(*zapi.VersionAuthError); !ok {
+		t.Fatalf("Expected VersionAuthError, got %#v", err)
+	}
+
+	calls = 0
+	api.Auth = "token"
+	v, err := api.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "7.0.0" || calls != 2 {
+		t.Errorf("Expected retry with auth, got %s after %d calls", v, calls)
+	}
+}
+
+func TestLoginDetailed(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return map[string]interface{}{"userid": "1", "username": "Admin", "autologout": "15m", "sessionid": "0424bd59b807674191e7d77572075f33"}, nil
+	})
+
+	session, err := api.LoginDetailed("Admin", "zabbix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.Auth != session.Token || session.UserID != "1" || session.Username != "Admin" {
+		t.Errorf("Bad session: %#v", session)
+	}
+	if d := time.Until(session.Expires); d <= 14*time.Minute || d > 15*time.Minute {
+		t.Errorf("Bad session expiry: %s", session.Expires)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	calls := []string{}
+	api := getMockAPI(t, zapi.Config{ReadOnly: true}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		calls = append(calls, method)
+		if strings.EqualFold(method, "apiinfo.version") {
+			return "6.0.21", nil
+		}
+		return []interface{}{}, nil
+	})
+
+	if _, err := api.HostGroupsGet(zapi.Params{}); err != nil {
+		t.Fatal(err)
+	}
+	err := api.HostGroupsCreate(zapi.HostGroups{{Name: "readonly"}})
+	if err != zapi.ErrReadOnly {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+	for _, method := range []string{"trigger.adddependencies", "history.clear", "user.unblock", "host.replacehostinterfaces"} {
+		if _, err = api.Call(method, zapi.Params{}); err != zapi.ErrReadOnly {
+			t.Errorf("%s: expected ErrReadOnly, got %v", method, err)
+		}
+	}
+	for _, method := range []string{"apiinfo.version", "sla.getsli", "configuration.export", "user.checkAuthentication"} {
+		if _, err = api.Call(method, zapi.Params{}); err != nil {
+			t.Errorf("%s: %v", method, err)
+		}
+	}
+	if v, err := api.Version(); err != nil || v != "6.0.21" {
+		t.Errorf("Version: %q, %v", v, err)
+	}
+	if len(calls) != 6 || calls[0] != "hostgroup.get" {
+		t.Errorf("Bad calls sent: %v", calls)
+	}
+}
+
+func TestRetryReadErrorCodes(t *testing.T) {
+	calls := map[string]int{}
+	api := getMockAPI(t, zapi.Config{RetryDelay: time.Millisecond}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		calls[method]++
+		if calls[method] == 1 {
+			return nil, &zapi.Error{Code: -32500, Message: "Application error.", Data: "SQL statement execution has failed."}
+		}
+		return []interface{}{}, nil
+	})
+
+	if _, err := api.HostGroupsGet(zapi.Params{}); err != nil || calls["hostgroup.get"] != 2 {
+		t.Errorf("Read not retried: %v, %d calls", err, calls["hostgroup.get"])
+	}
+	if err := api.HostGroupsCreate(zapi.HostGroups{{Name: "retry"}}); err == nil || calls["hostgroup.create"] != 1 {
+		t.Errorf("Write retried: %v, %d calls", err, calls["hostgroup.create"])
+	}
+}
+
+func TestRetryTransportFailures(t *testing.T) {
+	calls := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			ID     int32  `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		calls[req.Method]++
+		switch {
+		case req.Method == "hostgroup.get" && calls[req.Method] == 1:
+			// connection reset
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		case req.Method == "hostgroup.get" && calls[req.Method] == 2, req.Method == "hostgroup.create":
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		case req.Method == "host.get":
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "error": zapi.Error{Code: -32602, Message: "Invalid params."}, "id": req.ID})
+		case req.Method == "template.get":
+			json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "error": zapi.Error{Code: -32500, Message: "Application error."}, "id": req.ID})
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "result": []interface{}{}, "id": req.ID})
+		}
+	}))
+	defer srv.Close()
+
+	var logs strings.Builder
+	api := zapi.NewAPI(zapi.Config{
+		Url:        srv.URL,
+		Log:        log.New(&logs, "", 0),
+		Retry:      zapi.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond, Jitter: 0.5},
+		RetryCodes: []int{},
+	})
+
+	if _, err := api.HostGroupsGet(zapi.Params{}); err != nil || calls["hostgroup.get"] != 3 {
+		t.Errorf("Read not retried: %v, %d calls", err, calls["hostgroup.get"])
+	}
+	if !strings.Contains(logs.String(), "HTTP status 503, attempt 3 of 3") {
+		t.Errorf("Attempts not logged: %s", logs.String())
+	}
+	if _, err := api.HostsGet(zapi.Params{}); err == nil || calls["host.get"] != 1 {
+		t.Errorf("JSON-RPC error retried: %v, %d calls", err, calls["host.get"])
+	}
+	// error codes are only retried by RetryCodes, whatever MaxAttempts
+	if _, err := api.TemplatesGet(zapi.Params{}); err == nil || calls["template.get"] != 1 {
+		t.Errorf("JSON-RPC error retried with MaxAttempts: %v, %d calls", err, calls["template.get"])
+	}
+	if err := api.HostGroupsCreate(zapi.HostGroups{{Name: "retry"}}); err == nil || calls["hostgroup.create"] != 1 {
+		t.Errorf("Write retried: %v, %d calls", err, calls["hostgroup.create"])
+	}
+}
+
+func TestLogout(t *testing.T) {
+	calls := map[string]int{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		calls[method]++
+		switch method {
+		case "user.logout":
+			return true, nil
+		case "apiinfo.version":
+			return "6.0.21", nil
+		}
+		return map[string]string{"sessionid": "token", "userid": "1"}, nil
+	})
+
+	if err := api.Logout(); err != nil || calls["user.logout"] != 0 {
+		t.Errorf("Logout without session called the server: %v", err)
+	}
+
+	if _, err := api.LoginDetailed("Admin", "zabbix"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.ServerVersion(); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.Logout(); err != nil {
+		t.Fatal(err)
+	}
+	if calls["user.logout"] != 1 || api.Auth != "" || api.Session != nil {
+		t.Errorf("Session not cleared: %d calls, auth %q, session %#v", calls["user.logout"], api.Auth, api.Session)
+	}
+	if _, err := api.ServerVersion(); err != nil || calls["apiinfo.version"] != 2 {
+		t.Errorf("Version not detected again: %v, %d calls", err, calls["apiinfo.version"])
+	}
+}
+
+func TestAvailableMethods(t *testing.T) {
+	for _, test := range []struct {
+		version                     int
+		push, auditlog, application bool
+	}{
+		{50000, false, false, true},
+		{60000, false, true, false},
+		{70000, true, true, false},
+	} {
+		api := getMockAPI(t, zapi.Config{Version: test.version}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+			t.Fatalf("Unexpected call %s", method)
+			return nil, nil
+		})
+		methods, err := api.AvailableMethods()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if methods["history.push"] != test.push || methods["auditlog.get"] != test.auditlog ||
+			methods["application.get"] != test.application || !methods["host.get"] || !methods["event.acknowledge"] {
+			t.Errorf("Bad methods for %d: %v", test.version, methods)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	for _, tlsServer := range []bool{false, true} {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(300 * time.Millisecond)
+		})
+		var srv *httptest.Server
+		if tlsServer {
+			srv = httptest.NewTLSServer(handler)
+		} else {
+			srv = httptest.NewServer(handler)
+		}
+
+		api := zapi.NewAPI(zapi.Config{Url: srv.URL, TlsNoVerify: tlsServer, Timeout: 50 * time.Millisecond})
+		start := time.Now()
+		_, err := api.HostsGet(zapi.Params{})
+		elapsed := time.Since(start)
+		if e, ok := err.(net.Error); !ok || !e.Timeout() {
+			t.Errorf("TLS %v: expected timeout error, got %#v", tlsServer, err)
+		}
+		if elapsed > 250*time.Millisecond {
+			t.Errorf("TLS %v: timeout not applied, call took %s", tlsServer, elapsed)
+		}
+		srv.Close()
+	}
+}
+
+func TestLoginWithToken(t *testing.T) {
+	calls := map[string]int{}
+	reject := true
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		calls[method]++
+		switch method {
+		case "user.get":
+			if reject {
+				return nil, &zapi.Error{Code: -32602, Message: "Invalid params.", Data: "Not authorized."}
+			}
+			return []map[string]string{{"userid": "1"}}, nil
+		case "apiinfo.version":
+			return "6.0.21", nil
+		}
+		return "session", nil
+	})
+
+	if _, err := api.LoginWithToken("", "", "bad"); err == nil || api.Auth != "" {
+		t.Errorf("Expected refused token, got %v with auth %q", err, api.Auth)
+	}
+
+	reject = false
+	auth, err := api.LoginWithToken("", "", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth != "token" || api.Auth != "token" || calls["user.login"] != 0 || calls["apiinfo.version"] != 1 {
+		t.Errorf("Bad token login: auth %q, calls %v", api.Auth, calls)
+	}
+
+	if auth, err = api.LoginWithToken("Admin", "zabbix", ""); err != nil || auth != "session" || calls["user.login"] != 1 {
+		t.Errorf("Expected password login, got %q, %v", auth, err)
+	}
+}
+
+func TestGetOne(t *testing.T) {
+	var groups []map[string]string
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method != "hostgroup.get" {
+			t.Fatalf("Unexpected method %s", method)
+		}
+		return groups, nil
+	})
+
+	for _, n := range []int{0, 2} {
+		groups = make([]map[string]string, n)
+		for i := range groups {
+			groups[i] = map[string]string{"groupid": "2", "name": "Linux servers"}
+		}
+		var group zapi.HostGroup
+		err := api.GetOne("hostgroup.get", zapi.Params{"groupids": "2"}, &group)
+		if e, ok := err.(*zapi.ExpectedOneResult); !ok || int(*e) != n {
+			t.Errorf("Expected ExpectedOneResult(%d), got %v", n, err)
+		}
+	}
+
+	groups = []map[string]string{{"groupid": "2", "name": "Linux servers"}}
+	group, err := api.HostGroupGetByID("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.GroupID != "2" || group.Name != "Linux servers" {
+		t.Errorf("Bad group: %#v", group)
+	}
+}
+
+func TestMalformedResults(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return map[string]interface{}{"code": 502}, nil
+	})
+
+	if v, err := api.Version(); err == nil || v != "" {
+		t.Errorf("Expected error for malformed version, got %q", v)
+	}
+	if auth, err := api.Login("Admin", "zabbix"); err == nil || auth != "" || api.Auth != "" {
+		t.Errorf("Expected error for malformed login, got %q", auth)
+	}
+}
+
+func TestResultIDs(t *testing.T) {
+	var result interface{}
+	api := getMockAPI(t, zapi.Config{Version: 50000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return result, nil
+	})
+
+	// object of ids keyed by index, in an order a map range does not keep
+	object, ordered := map[string]interface{}{}, []string{}
+	for i := 0; i < 12; i++ {
+		object[fmt.Sprint(i)] = fmt.Sprint(100 + i)
+		ordered = append(ordered, fmt.Sprint(100+i))
+	}
+	for _, test := range []struct {
+		name     string
+		result   interface{}
+		expected []string
+	}{
+		{"array", map[string]interface{}{"triggerids": []interface{}{"7", 8}}, []string{"7", "8"}},
+		{"object", map[string]interface{}{"triggerids": object}, ordered},
+		{"empty", map[string]interface{}{"triggerids": []interface{}{}}, nil},
+		{"missing", map[string]interface{}{}, nil},
+		{"non-map", []interface{}{"7"}, nil},
+		{"non-string", map[string]interface{}{"triggerids": []interface{}{map[string]string{"triggerid": "7"}}}, nil},
+	} {
+		result = test.result
+		triggers := zapi.Triggers{{Description: "Down", Expression: "last(/host/agent.ping)=0"}}
+		for len(triggers) < len(test.expected) {
+			triggers = append(triggers, triggers[0])
+		}
+		err := api.TriggersCreate(triggers)
+		if test.expected == nil {
+			if err == nil {
+				t.Errorf("%s: expected error", test.name)
+			}
+			continue
+		}
+		ids := []string{}
+		for _, trigger := range triggers {
+			ids = append(ids, trigger.TriggerID)
+		}
+		if err != nil || !reflect.DeepEqual(ids, test.expected) {
+			t.Errorf("%s: expected %v, got %v %v", test.name, test.expected, ids, err)
+		}
+	}
+}
+
+func TestLoginUsernameField(t *testing.T) {
+	for _, test := range []struct {
+		version string
+		fields  []string
+	}{
+		{"6.4.0", []string{"username"}},
+		{"5.0.30", []string{"user"}},
+		{"", []string{"username", "user"}},
+	} {
+		fields := []string{}
+		api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+			if method == "apiinfo.version" {
+				if test.version == "" {
+					return nil, &zapi.Error{Code: -32602, Message: "Invalid params.", Data: "Not authorised."}
+				}
+				return test.version, nil
+			}
+			var p map[string]string
+			json.Unmarshal(params, &p)
+			if _, ok := p["username"]; ok {
+				fields = append(fields, "username")
+				if test.version == "" {
+					return nil, &zapi.Error{Code: -32602, Message: "Invalid params.", Data: `Invalid parameter "/": unexpected parameter "username".`}
+				}
+			}
+			if _, ok := p["user"]; ok {
+				fields = append(fields, "user")
+			}
+			return "token", nil
+		})
+
+		if auth, err := api.Login("Admin", "zabbix"); err != nil || auth != "token" {
+			t.Fatalf("%s: login failed: %v", test.version, err)
+		}
+		if !reflect.DeepEqual(fields, test.fields) {
+			t.Errorf("%s: expected %v sent, got %v", test.version, test.fields, fields)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package zabbix
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BrowserItem represent Zabbix item object of the Browser type, Zabbix 7.0+
+// https://www.zabbix.com/documentation/7.0/manual/api/reference/item/object
+type BrowserItem struct {
+	ItemID      string     `json:"itemid,omitempty"`
+	HostID      string     `json:"hostid"`
+	Key         string     `json:"key_"`
+	Name        string     `json:"name"`
+	Type        ItemType   `json:"type,string"`
+	ValueType   ValueType  `json:"value_type,string"`
+	Delay       string     `json:"delay"`
+	Timeout     string     `json:"timeout,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Status      StatusType `json:"status,string"`
+	// BrowserScript JavaScript code driving the browser
+	BrowserScript string `json:"params"`
+	// Parameters passed to the script
+	Parameters ItemParameters `json:"parameters,omitempty"`
+	Tags       Tags           `json:"tags,omitempty"`
+}
+
+// BrowserItems is an array of BrowserItem
+type BrowserItems []BrowserItem
+
+// ValidateBrowserItem checks a browser item has a key and a script
+func ValidateBrowserItem(item BrowserItem) error {
+	if item.Key == "" {
+		return fmt.Errorf("Browser item %s: missing key", item.Name)
+	}
+	if strings.TrimSpace(item.BrowserScript) == "" {
+		return fmt.Errorf("Browser item %s: missing BrowserScript", item.Key)
+	}
+	return nil
+}
+
+// CreateBrowserItems Wrapper for item.create, Zabbix 7.0+
+// The type of the items is set to Browser.
+// https://www.zabbix.com/documentation/7.0/manual/api/reference/item/create
+func (api *API) CreateBrowserItems(items BrowserItems) (err error) {
+	for i := range items {
+		items[i].Type = Browser
+		if err = ValidateBrowserItem(items[i]); err != nil {
+			return
+		}
+	}
+	v, err := api.ServerVersion()
+	if err != nil {
+		return
+	}
+	if v < 70000 {
+		return fmt.Errorf("browser items need Zabbix 7.0, server is %d", v)
+	}
+
+	response, err := api.CallWithError("item.create", items)
+	if err != nil {
+		return
+	}
+
+	itemids, err := resultIDs(response, "itemids")
+	if err != nil {
+		return
+	}
+	if len(itemids) != len(items) {
+		return &ExpectedMore{len(items), len(itemids)}
+	}
+	for i, id := range itemids {
+		items[i].ItemID = id
+	}
+	return
+}
+
+// GetBrowserItems Wrapper for item.get, restricted to Browser items
+// https://www.zabbix.com/documentation/7.0/manual/api/reference/item/get
+func (api *API) GetBrowserItems(params Params) (res BrowserItems, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	filter := map[string]interface{}{}
+	if f, ok := params["filter"].(map[string]interface{}); ok {
+		for k, v := range f {
+			filter[k] = v
+		}
+	}
+	filter["type"] = Browser
+	params["filter"] = filter
+	err = api.CallWithErrorParse("item.get", params, &res)
+	return
+}
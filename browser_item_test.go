@@ -0,0 +1,56 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestBrowserItems(t *testing.T) {
+	var sent []map[string]interface{}
+	var filter map[string]interface{}
+	api := getMockAPI(t, zapi.Config{Version: 70000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "item.create":
+			json.Unmarshal(params, &sent)
+			return map[string][]string{"itemids": {"42"}}, nil
+		case "item.get":
+			var p struct {
+				Filter map[string]interface{} `json:"filter"`
+			}
+			json.Unmarshal(params, &p)
+			filter = p.Filter
+			return []interface{}{map[string]interface{}{
+				"itemid": "42", "hostid": "10084", "key_": "web.login", "name": "Login",
+				"type": "22", "value_type": "4", "params": "const b = new Browser();",
+			}}, nil
+		}
+		t.Fatalf("Unexpected call to %s", method)
+		return nil, nil
+	})
+
+	items := zapi.BrowserItems{{HostID: "10084", Key: "web.login", Name: "Login", ValueType: zapi.Text}}
+	if err := api.CreateBrowserItems(items); err == nil {
+		t.Error("Expected error for browser item without script")
+	}
+
+	items[0].BrowserScript = "const b = new Browser();"
+	if err := api.CreateBrowserItems(items); err != nil {
+		t.Fatal(err)
+	}
+	if items[0].ItemID != "42" || len(sent) != 1 || sent[0]["type"] != "22" || sent[0]["params"] != items[0].BrowserScript {
+		t.Errorf("Bad item sent: %#v", sent)
+	}
+
+	res, err := api.GetBrowserItems(zapi.Params{"hostids": "10084", "filter": map[string]interface{}{"key_": "web.login"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter["type"] != float64(22) || filter["key_"] != "web.login" {
+		t.Errorf("Bad filter: %v", filter)
+	}
+	if len(res) != 1 || res[0].Type != zapi.Browser || res[0].BrowserScript != items[0].BrowserScript {
+		t.Errorf("Bad browser items: %#v", res)
+	}
+}
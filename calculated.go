@@ -0,0 +1,157 @@
+package zabbix
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CalculatedFormula is the formula of a calculated item, set in its Params,
+// built from CalcFunc, plain numbers and operators
+// https://www.zabbix.com/documentation/6.0/manual/config/items/itemtypes/calculated
+type CalculatedFormula string
+
+// CalcFunc returns the function applied to the key of host, the host of the item when empty,
+// with the other parameters following the item reference, avg(/host/key,5m)
+func CalcFunc(function, host, key string, params ...string) CalculatedFormula {
+	return CalculatedFormula(fmt.Sprintf("%s(%s)", function, strings.Join(append([]string{"/" + host + "/" + key}, params...), ",")))
+}
+
+// With returns the formula combined with other by the operator, "+", "*", "and"...
+func (f CalculatedFormula) With(operator string, other CalculatedFormula) CalculatedFormula {
+	return f + CalculatedFormula(" "+operator+" ") + other
+}
+
+// Group returns the formula between parentheses
+func (f CalculatedFormula) Group() CalculatedFormula {
+	return "(" + f + ")"
+}
+
+var (
+	// calcHostRegexp matches the host of an item reference, possibly a macro or a wildcard
+	calcHostRegexp = regexp.MustCompile(`^(\{[^}]*\}|[0-9A-Za-z_. \-*])*`)
+	// calcKeyRegexp matches the name of an item key, before its parameters
+	calcKeyRegexp = regexp.MustCompile(`^[0-9A-Za-z_.\-*]+`)
+)
+
+// ValidateCalculatedFormula Checks the formula of a calculated item: strings are terminated,
+// parentheses balanced and item references, the first parameter of functions, follow
+// the /host/key syntax of Zabbix 5.4+, with an optional ?[filter] for aggregates.
+func ValidateCalculatedFormula(params string) error {
+	if strings.TrimSpace(params) == "" {
+		return fmt.Errorf("Calculated formula is empty")
+	}
+	depth, reference, afterReference := 0, false, false
+	for i := 0; i < len(params); i++ {
+		c := params[i]
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			continue
+		}
+		if afterReference && c != ',' && c != ')' {
+			return fmt.Errorf("Calculated formula: unexpected %q after item reference at %d", c, i)
+		}
+		afterReference = false
+		switch c {
+		case '"':
+			n := quotedLength(params[i:])
+			if n < 0 {
+				return fmt.Errorf("Calculated formula: unterminated string at %d", i)
+			}
+			i += n - 1
+			reference = false
+		case '(':
+			depth++
+			reference = true
+		case ',':
+			if depth == 0 {
+				return fmt.Errorf("Calculated formula: ',' outside of a function at %d", i)
+			}
+			reference = true
+		case ')':
+			if depth--; depth < 0 {
+				return fmt.Errorf("Calculated formula: unbalanced ')' at %d", i)
+			}
+			reference = false
+		case '/':
+			if !reference {
+				continue
+			}
+			n, err := itemReferenceLength(params[i:])
+			if err != nil {
+				return fmt.Errorf("Calculated formula: %s at %d", err, i)
+			}
+			i += n - 1
+			reference, afterReference = false, true
+		default:
+			reference = false
+		}
+	}
+	if depth > 0 {
+		return fmt.Errorf("Calculated formula: %d unclosed '('", depth)
+	}
+	return nil
+}
+
+// quotedLength returns the length of the string starting s, quotes included, -1 if unterminated
+func quotedLength(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// bracketLength returns the length of the bracketed list starting s, nested lists
+// and quoted strings included, -1 if unterminated
+func bracketLength(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			n := quotedLength(s[i:])
+			if n < 0 {
+				return -1
+			}
+			i += n - 1
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// itemReferenceLength returns the length of the /host/key[params]?[filter] item reference starting s
+func itemReferenceLength(s string) (int, error) {
+	n := 1 + len(calcHostRegexp.FindString(s[1:]))
+	if n >= len(s) || s[n] != '/' {
+		return 0, fmt.Errorf("item reference is not /host/key")
+	}
+	key := calcKeyRegexp.FindString(s[n+1:])
+	if key == "" {
+		return 0, fmt.Errorf("item reference has no key")
+	}
+	n += 1 + len(key)
+	if n < len(s) && s[n] == '[' {
+		l := bracketLength(s[n:])
+		if l < 0 {
+			return 0, fmt.Errorf("unterminated key parameters")
+		}
+		n += l
+	}
+	if strings.HasPrefix(s[n:], "?[") {
+		l := bracketLength(s[n+1:])
+		if l < 0 {
+			return 0, fmt.Errorf("unterminated filter")
+		}
+		n += 1 + l
+	}
+	return n, nil
+}
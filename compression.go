@@ -0,0 +1,99 @@
+package zabbix
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// defaultAcceptedEncodings are the encodings accepted when Config.EnableCompression
+// is set without Config.AcceptedEncodings
+var defaultAcceptedEncodings = []string{"gzip", "deflate", "identity"}
+
+// compressionTransport asks for compressed responses and decodes them.
+// Once a response advertises gzip in its Accept-Encoding header,
+// request bodies are gzip encoded too.
+type compressionTransport struct {
+	transport         http.RoundTripper
+	acceptedEncodings []string
+	gzipRequests      int32
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *compressionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := *req
+	r.Header = make(http.Header, len(req.Header)+2)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	r.Header.Set("Accept-Encoding", strings.Join(t.acceptedEncodings, ", "))
+
+	if atomic.LoadInt32(&t.gzipRequests) == 1 && req.Body != nil && req.Header.Get("Content-Encoding") == "" {
+		body, err := gzipBody(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.GetBody = func() (io.ReadCloser, error) { return ioutil.NopCloser(bytes.NewReader(body)), nil }
+		r.ContentLength = int64(len(body))
+		r.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := t.transport.RoundTrip(&r)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(resp.Header.Get("Accept-Encoding"), "gzip") {
+		atomic.StoreInt32(&t.gzipRequests, 1)
+	}
+
+	var decoded io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip":
+		decoded, err = gzip.NewReader(resp.Body)
+	case "deflate":
+		decoded, err = zlib.NewReader(resp.Body)
+	default:
+		return resp, nil
+	}
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	resp.Body = &decodedBody{decoded, resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// gzipBody reads and closes body, returning it gzip encoded
+func gzipBody(body io.ReadCloser) ([]byte, error) {
+	defer body.Close()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := io.Copy(w, body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodedBody reads the decoder and closes both it and the raw body
+type decodedBody struct {
+	io.ReadCloser
+	raw io.ReadCloser
+}
+
+func (b *decodedBody) Close() error {
+	b.ReadCloser.Close()
+	return b.raw.Close()
+}
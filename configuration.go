@@ -0,0 +1,189 @@
+package zabbix
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// ImportRule tells how objects of one type are imported
+type ImportRule struct {
+	CreateMissing  bool `json:"createMissing,omitempty"`
+	UpdateExisting bool `json:"updateExisting,omitempty"`
+	DeleteMissing  bool `json:"deleteMissing,omitempty"`
+}
+
+// ImportRules are the rules by object type, types left nil are not imported
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/configuration/import
+type ImportRules struct {
+	// HostGroups is sent as host_groups since Zabbix 6.2, and as template_groups
+	// too unless TemplateGroups is set
+	HostGroups *ImportRule `json:"groups,omitempty"`
+	// TemplateGroups is for Zabbix 6.2+, sent as groups before when HostGroups is nil
+	TemplateGroups *ImportRule `json:"template_groups,omitempty"`
+	// RawHostGroups is set from HostGroups on Zabbix 6.2+
+	RawHostGroups *ImportRule `json:"host_groups,omitempty"`
+
+	Hosts              *ImportRule `json:"hosts,omitempty"`
+	Templates          *ImportRule `json:"templates,omitempty"`
+	TemplateLinkage    *ImportRule `json:"templateLinkage,omitempty"`
+	TemplateDashboards *ImportRule `json:"templateDashboards,omitempty"`
+	Items              *ImportRule `json:"items,omitempty"`
+	Triggers           *ImportRule `json:"triggers,omitempty"`
+	Graphs             *ImportRule `json:"graphs,omitempty"`
+	DiscoveryRules     *ImportRule `json:"discoveryRules,omitempty"`
+	HTTPTests          *ImportRule `json:"httptests,omitempty"`
+	ValueMaps          *ImportRule `json:"valueMaps,omitempty"`
+	Maps               *ImportRule `json:"maps,omitempty"`
+	Images             *ImportRule `json:"images,omitempty"`
+	MediaTypes         *ImportRule `json:"mediaTypes,omitempty"`
+}
+
+// ImportOptions are the parameters of configuration.import and configuration.importcompare
+type ImportOptions struct {
+	// Format is "yaml", "xml" or "json"
+	Format string      `json:"format"`
+	Source string      `json:"source"`
+	Rules  ImportRules `json:"rules"`
+}
+
+// ImportChanges are the changes of one object type found by configuration.importcompare,
+// added and removed objects are kept as sent by the server
+type ImportChanges struct {
+	Added   []json.RawMessage `json:"added,omitempty"`
+	Removed []json.RawMessage `json:"removed,omitempty"`
+	Updated []ImportUpdate    `json:"updated,omitempty"`
+}
+
+// ImportUpdate is an updated object, along with the changes of the objects it holds
+type ImportUpdate struct {
+	Before json.RawMessage
+	After  json.RawMessage
+	// Children are the changes of the objects of the updated one, like its items
+	Children ImportDiff
+}
+
+// UnmarshalJSON splits before and after from the changes of the children
+func (u *ImportUpdate) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	u.Before, u.After = fields["before"], fields["after"]
+	delete(fields, "before")
+	delete(fields, "after")
+	if len(fields) == 0 {
+		return nil
+	}
+
+	u.Children = make(ImportDiff, len(fields))
+	for key, raw := range fields {
+		var changes ImportChanges
+		if err := json.Unmarshal(raw, &changes); err != nil {
+			return err
+		}
+		u.Children[key] = changes
+	}
+	return nil
+}
+
+// ImportDiff are the changes an import would make, by object type like "templates"
+type ImportDiff map[string]ImportChanges
+
+// ImportError is an import refused by the server, with the path of the faulty element when known
+type ImportError struct {
+	// Path is like "/zabbix_export/templates/template(1)/items/item(2)/key"
+	Path   string
+	Reason string
+	Err    *Error
+}
+
+func (e *ImportError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("Import failed at %s: %s", e.Path, e.Reason)
+}
+
+// prepImportRules adapts the group rules to the server version,
+// host and template groups being split since Zabbix 6.2
+func (api *API) prepImportRules(rules *ImportRules) error {
+	if rules.HostGroups == nil && rules.TemplateGroups == nil {
+		return nil
+	}
+	v, err := api.ServerVersion()
+	if err != nil {
+		return err
+	}
+	if v < 60200 {
+		if rules.HostGroups == nil {
+			rules.HostGroups = rules.TemplateGroups
+		}
+		rules.TemplateGroups = nil
+		return nil
+	}
+	rules.RawHostGroups, rules.HostGroups = rules.HostGroups, nil
+	if rules.TemplateGroups == nil {
+		rules.TemplateGroups = rules.RawHostGroups
+	}
+	return nil
+}
+
+// importErrorRegexp matches import errors naming the faulty element
+var importErrorRegexp = regexp.MustCompile(`^Invalid (?:tag|parameter) "([^"]+)": (.+)$`)
+
+// importError wraps the errors of the server in an ImportError
+func importError(err error) error {
+	e, ok := err.(*Error)
+	if !ok {
+		return err
+	}
+	res := &ImportError{Reason: e.Data, Err: e}
+	if m := importErrorRegexp.FindStringSubmatch(e.Data); m != nil {
+		res.Path, res.Reason = m[1], m[2]
+	}
+	return res
+}
+
+// ConfigurationImport Wrapper for configuration.import
+// Errors of the server are returned as ImportError.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/configuration/import
+func (api *API) ConfigurationImport(opts ImportOptions) (err error) {
+	if err = api.prepImportRules(&opts.Rules); err != nil {
+		return
+	}
+	var ok bool
+	if err = api.CallWithErrorParse("configuration.import", opts, &ok); err != nil {
+		return importError(err)
+	}
+	return
+}
+
+// ConfigurationImportCompare Wrapper for configuration.importcompare, Zabbix 6.0+
+// Returns the changes the import would make, nothing is applied.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/configuration/importcompare
+func (api *API) ConfigurationImportCompare(opts ImportOptions) (res *ImportDiff, err error) {
+	v, err := api.ServerVersion()
+	if err != nil {
+		return
+	}
+	if v < 60000 {
+		return nil, fmt.Errorf("configuration.importcompare needs Zabbix 6.0, server is %d", v)
+	}
+	if err = api.prepImportRules(&opts.Rules); err != nil {
+		return
+	}
+
+	// no change is an empty array rather than an object
+	var raw json.RawMessage
+	if err = api.CallWithErrorParse("configuration.importcompare", opts, &raw); err != nil {
+		return nil, importError(err)
+	}
+	diff := ImportDiff{}
+	if string(raw) != "[]" {
+		if err = json.Unmarshal(raw, &diff); err != nil {
+			return
+		}
+	}
+	return &diff, nil
+}
@@ -0,0 +1,97 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestConfigurationImportCompare(t *testing.T) {
+	var sent map[string]interface{}
+	api := getMockAPI(t, zapi.Config{Version: 60000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return json.RawMessage(`{"templates": {"updated": [{
+			"before": {"uuid": "e2307c94", "template": "Linux"},
+			"after": {"uuid": "e2307c94", "template": "Linux"},
+			"items": {
+				"added": [{"uuid": "a1", "name": "CPU load", "key": "system.cpu.load"}],
+				"updated": [{"before": {"uuid": "a2", "delay": "1m"}, "after": {"uuid": "a2", "delay": "30s"}}]
+			}
+		}]}}`), nil
+	})
+
+	diff, err := api.ConfigurationImportCompare(zapi.ImportOptions{
+		Format: "yaml",
+		Source: "zabbix_export: ...",
+		Rules:  zapi.ImportRules{Templates: &zapi.ImportRule{CreateMissing: true, UpdateExisting: true}, Items: &zapi.ImportRule{CreateMissing: true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := sent["rules"].(map[string]interface{})
+	if _, ok := rules["hosts"]; ok || rules["items"] == nil {
+		t.Errorf("Bad rules: %#v", rules)
+	}
+
+	templates := (*diff)["templates"]
+	if len(templates.Updated) != 1 || len(templates.Added) != 0 {
+		t.Fatalf("Bad diff: %#v", diff)
+	}
+	items := templates.Updated[0].Children["items"]
+	if len(items.Added) != 1 || len(items.Updated) != 1 || string(items.Updated[0].After) != `{"uuid":"a2","delay":"30s"}` {
+		t.Errorf("Bad items diff: %#v", items)
+	}
+}
+
+func TestConfigurationImportError(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{Version: 50000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return nil, &zapi.Error{Code: -32602, Message: "Invalid params.", Data: `Invalid tag "/zabbix_export/templates/template(1)/items/item(2)/key": cannot be empty.`}
+	})
+
+	err := api.ConfigurationImport(zapi.ImportOptions{Format: "yaml", Source: "zabbix_export: ..."})
+	importErr, ok := err.(*zapi.ImportError)
+	if !ok {
+		t.Fatalf("Expected ImportError, got %#v", err)
+	}
+	if importErr.Path != "/zabbix_export/templates/template(1)/items/item(2)/key" || importErr.Reason != "cannot be empty." {
+		t.Errorf("Bad import error: %#v", importErr)
+	}
+
+	if _, err = api.ConfigurationImportCompare(zapi.ImportOptions{}); err == nil {
+		t.Error("Expected importcompare to be refused before 6.0")
+	}
+}
+
+func TestConfigurationImportGroupRules(t *testing.T) {
+	for _, c := range []struct {
+		version  int
+		rules    zapi.ImportRules
+		expected []string
+	}{
+		{60000, zapi.ImportRules{HostGroups: &zapi.ImportRule{CreateMissing: true}}, []string{"groups"}},
+		{60000, zapi.ImportRules{TemplateGroups: &zapi.ImportRule{CreateMissing: true}}, []string{"groups"}},
+		{60200, zapi.ImportRules{HostGroups: &zapi.ImportRule{CreateMissing: true}}, []string{"host_groups", "template_groups"}},
+		{60200, zapi.ImportRules{TemplateGroups: &zapi.ImportRule{CreateMissing: true}}, []string{"template_groups"}},
+	} {
+		var sent struct {
+			Rules map[string]zapi.ImportRule `json:"rules"`
+		}
+		api := getMockAPI(t, zapi.Config{Version: c.version}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+			json.Unmarshal(params, &sent)
+			return true, nil
+		})
+
+		if err := api.ConfigurationImport(zapi.ImportOptions{Format: "yaml", Source: "zabbix_export: ...", Rules: c.rules}); err != nil {
+			t.Fatal(err)
+		}
+		if len(sent.Rules) != len(c.expected) {
+			t.Errorf("Bad rules on %d: %#v", c.version, sent.Rules)
+		}
+		for _, key := range c.expected {
+			if !sent.Rules[key].CreateMissing {
+				t.Errorf("Rule %s missing on %d: %#v", key, c.version, sent.Rules)
+			}
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package zabbix
+
+import "fmt"
+
+type (
+	// WidgetFieldType type of a dashboard widget field
+	// see "type" in https://www.zabbix.com/documentation/6.0/manual/api/reference/dashboard/object#dashboard-widget-field
+	WidgetFieldType int
+
+	// WidgetViewMode display of a dashboard widget
+	// see "view_mode" in https://www.zabbix.com/documentation/6.0/manual/api/reference/dashboard/object#dashboard-widget
+	WidgetViewMode int
+)
+
+const (
+	// WidgetFieldInt integer
+	WidgetFieldInt WidgetFieldType = 0
+	// WidgetFieldString string
+	WidgetFieldString WidgetFieldType = 1
+	// WidgetFieldHostGroupID host group id
+	WidgetFieldHostGroupID WidgetFieldType = 2
+	// WidgetFieldHostID host id
+	WidgetFieldHostID WidgetFieldType = 3
+	// WidgetFieldItemID item id
+	WidgetFieldItemID WidgetFieldType = 4
+	// WidgetFieldItemPrototypeID item prototype id
+	WidgetFieldItemPrototypeID WidgetFieldType = 5
+	// WidgetFieldGraphID graph id
+	WidgetFieldGraphID WidgetFieldType = 6
+	// WidgetFieldGraphPrototypeID graph prototype id
+	WidgetFieldGraphPrototypeID WidgetFieldType = 7
+	// WidgetFieldMapID map id
+	WidgetFieldMapID WidgetFieldType = 8
+)
+
+const (
+	// WidgetViewDefault default widget view (default)
+	WidgetViewDefault WidgetViewMode = 0
+	// WidgetViewHiddenHeader widget header hidden
+	WidgetViewHiddenHeader WidgetViewMode = 1
+)
+
+// WidgetField represent Zabbix dashboard widget field object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/dashboard/object#dashboard-widget-field
+type WidgetField struct {
+	Type  WidgetFieldType `json:"type,string"`
+	Name  string          `json:"name"`
+	Value string          `json:"value"`
+}
+
+// WidgetFields is an array of WidgetField
+type WidgetFields []WidgetField
+
+// Widget represent Zabbix dashboard widget object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/dashboard/object#dashboard-widget
+type Widget struct {
+	WidgetID string         `json:"widgetid,omitempty"`
+	Type     string         `json:"type"`
+	Name     string         `json:"name,omitempty"`
+	ViewMode WidgetViewMode `json:"view_mode,omitempty,string"`
+	X        string         `json:"x,omitempty"`
+	Y        string         `json:"y,omitempty"`
+	Width    string         `json:"width,omitempty"`
+	Height   string         `json:"height,omitempty"`
+	Fields   WidgetFields   `json:"fields,omitempty"`
+}
+
+// Widgets is an array of Widget
+type Widgets []Widget
+
+// DashboardPage represent Zabbix dashboard page object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/dashboard/object#dashboard-page
+type DashboardPage struct {
+	DashboardPageID string  `json:"dashboard_pageid,omitempty"`
+	Name            string  `json:"name,omitempty"`
+	DisplayPeriod   string  `json:"display_period,omitempty"`
+	Widgets         Widgets `json:"widgets,omitempty"`
+}
+
+// DashboardPages is an array of DashboardPage
+type DashboardPages []DashboardPage
+
+// NewGraphWidget Builds a classic graph widget showing the graph, to be placed with X, Y, Width and Height
+func NewGraphWidget(name, graphID string) Widget {
+	return Widget{
+		Type: "graph",
+		Name: name,
+		Fields: WidgetFields{
+			// source_type 0 is a graph, 1 a simple graph of an item
+			{Type: WidgetFieldInt, Name: "source_type", Value: "0"},
+			{Type: WidgetFieldGraphID, Name: "graphid", Value: graphID},
+		},
+	}
+}
+
+// NewProblemsWidget Builds a problems widget, showing the given severities or all when none,
+// to be placed with X, Y, Width and Height
+func NewProblemsWidget(name string, severities ...SeverityType) Widget {
+	w := Widget{Type: "problems", Name: name}
+	for _, s := range severities {
+		w.Fields = append(w.Fields, WidgetField{Type: WidgetFieldInt, Name: "severities", Value: fmt.Sprint(int(s))})
+	}
+	return w
+}
+
+// Dashboard represent Zabbix dashboard object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/dashboard/object
+type Dashboard struct {
+	DashboardID   string         `json:"dashboardid,omitempty"`
+	Name          string         `json:"name"`
+	UserID        string         `json:"userid,omitempty"`
+	Private       string         `json:"private,omitempty"`
+	DisplayPeriod string         `json:"display_period,omitempty"`
+	AutoStart     string         `json:"auto_start,omitempty"`
+	Pages         DashboardPages `json:"pages,omitempty"`
+}
+
+// Dashboards is an array of Dashboard
+type Dashboards []Dashboard
+
+// DashboardsGet Wrapper for dashboard.get
+// Pages and their widgets are selected unless asked otherwise.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/dashboard/get
+func (api *API) DashboardsGet(params Params) (res Dashboards, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	if _, present := params["selectPages"]; !present {
+		params["selectPages"] = "extend"
+	}
+	err = api.CallWithErrorParse("dashboard.get", params, &res)
+	return
+}
+
+// DashboardsCreate Wrapper for dashboard.create
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/dashboard/create
+func (api *API) DashboardsCreate(dashboards Dashboards) (err error) {
+	response, err := api.CallWithError("dashboard.create", dashboards)
+	if err != nil {
+		return
+	}
+
+	dashboardids, err := resultIDs(response, "dashboardids")
+	if err != nil {
+		return
+	}
+	if len(dashboardids) != len(dashboards) {
+		return &ExpectedMore{len(dashboards), len(dashboardids)}
+	}
+	for i, id := range dashboardids {
+		dashboards[i].DashboardID = id
+	}
+	return
+}
+
+// DashboardsUpdate Wrapper for dashboard.update
+// Pages and widgets left out are deleted, send them all back.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/dashboard/update
+func (api *API) DashboardsUpdate(dashboards Dashboards) (err error) {
+	_, err = api.CallWithError("dashboard.update", dashboards)
+	return
+}
+
+// DashboardsDeleteByIds Wrapper for dashboard.delete
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/dashboard/delete
+func (api *API) DashboardsDeleteByIds(ids []string) (err error) {
+	response, err := api.CallWithError("dashboard.delete", ids)
+	if err != nil {
+		return
+	}
+
+	dashboardids, err := resultIDs(response, "dashboardids")
+	if err != nil {
+		return
+	}
+	if len(ids) != len(dashboardids) {
+		err = &ExpectedMore{len(ids), len(dashboardids)}
+	}
+	return
+}
+
+// TemplateDashboard represent Zabbix template dashboard object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/templatedashboard/object
+type TemplateDashboard struct {
+	DashboardID   string         `json:"dashboardid,omitempty"`
+	TemplateID    string         `json:"templateid,omitempty"`
+	Name          string         `json:"name"`
+	DisplayPeriod string         `json:"display_period,omitempty"`
+	AutoStart     string         `json:"auto_start,omitempty"`
+	UUID          string         `json:"uuid,omitempty"`
+	Pages         DashboardPages `json:"pages,omitempty"`
+}
+
+// TemplateDashboards is an array of TemplateDashboard
+type TemplateDashboards []TemplateDashboard
+
+// TemplateDashboardsGet Wrapper for templatedashboard.get
+// Pages and their widgets are selected unless asked otherwise.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/templatedashboard/get
+func (api *API) TemplateDashboardsGet(params Params) (res TemplateDashboards, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	if _, present := params["selectPages"]; !present {
+		params["selectPages"] = "extend"
+	}
+	err = api.CallWithErrorParse("templatedashboard.get", params, &res)
+	return
+}
+
+// TemplateDashboardsCreate Wrapper for templatedashboard.create
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/templatedashboard/create
+func (api *API) TemplateDashboardsCreate(dashboards TemplateDashboards) (err error) {
+	response, err := api.CallWithError("templatedashboard.create", dashboards)
+	if err != nil {
+		return
+	}
+
+	dashboardids, err := resultIDs(response, "dashboardids")
+	if err != nil {
+		return
+	}
+	if len(dashboardids) != len(dashboards) {
+		return &ExpectedMore{len(dashboards), len(dashboardids)}
+	}
+	for i, id := range dashboardids {
+		dashboards[i].DashboardID = id
+	}
+	return
+}
+
+// TemplateDashboardsUpdate Wrapper for templatedashboard.update
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/templatedashboard/update
+func (api *API) TemplateDashboardsUpdate(dashboards TemplateDashboards) (err error) {
+	_, err = api.CallWithError("templatedashboard.update", dashboards)
+	return
+}
+
+// TemplateDashboardsDelete Wrapper for templatedashboard.delete
+// Cleans DashboardID in all dashboards elements if call succeed.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/templatedashboard/delete
+func (api *API) TemplateDashboardsDelete(dashboards TemplateDashboards) (err error) {
+	ids := make([]string, len(dashboards))
+	for i, dashboard := range dashboards {
+		ids[i] = dashboard.DashboardID
+	}
+
+	err = api.TemplateDashboardsDeleteByIds(ids)
+	if err == nil {
+		for i := range dashboards {
+			dashboards[i].DashboardID = ""
+		}
+	}
+	return
+}
+
+// TemplateDashboardsDeleteByIds Wrapper for templatedashboard.delete
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/templatedashboard/delete
+func (api *API) TemplateDashboardsDeleteByIds(ids []string) (err error) {
+	response, err := api.CallWithError("templatedashboard.delete", ids)
+	if err != nil {
+		return
+	}
+
+	dashboardids, err := resultIDs(response, "dashboardids")
+	if err != nil {
+		return
+	}
+	if len(ids) != len(dashboardids) {
+		err = &ExpectedMore{len(ids), len(dashboardids)}
+	}
+	return
+}
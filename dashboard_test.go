@@ -0,0 +1,82 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestTemplateDashboardsCreate(t *testing.T) {
+	var sent zapi.TemplateDashboards
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"dashboardids": {"12"}}, nil
+	})
+
+	dashboards := zapi.TemplateDashboards{{
+		TemplateID: "10001",
+		Name:       "System performance",
+		Pages: zapi.DashboardPages{{
+			Widgets: zapi.Widgets{{
+				Type:   "graph",
+				Width:  "12",
+				Height: "5",
+				Fields: zapi.WidgetFields{{Type: 6, Name: "graphid", Value: "524"}},
+			}},
+		}},
+	}}
+	if err := api.TemplateDashboardsCreate(dashboards); err != nil {
+		t.Fatal(err)
+	}
+	if dashboards[0].DashboardID != "12" {
+		t.Errorf("Dashboard id not filled: %#v", dashboards[0])
+	}
+	if len(sent) != 1 || sent[0].Pages[0].Widgets[0].Fields[0].Value != "524" {
+		t.Errorf("Bad dashboard sent: %#v", sent)
+	}
+}
+
+func TestDashboardsCreateTwoPages(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"dashboardids": {"5"}}, nil
+	})
+
+	graph := zapi.NewGraphWidget("CPU", "524")
+	graph.Width, graph.Height = "12", "5"
+	graph.ViewMode = zapi.WidgetViewHiddenHeader
+	problems := zapi.NewProblemsWidget("Severe problems", zapi.High, zapi.Critical)
+	problems.Width, problems.Height = "24", "8"
+
+	dashboards := zapi.Dashboards{{
+		Name:          "Web servers",
+		DisplayPeriod: "60",
+		AutoStart:     "1",
+		Pages: zapi.DashboardPages{
+			{Name: "Performance", DisplayPeriod: "30", Widgets: zapi.Widgets{graph}},
+			{Name: "Problems", Widgets: zapi.Widgets{problems}},
+		},
+	}}
+	if err := api.DashboardsCreate(dashboards); err != nil {
+		t.Fatal(err)
+	}
+	if dashboards[0].DashboardID != "5" {
+		t.Errorf("Dashboard id not filled: %#v", dashboards[0])
+	}
+
+	pages := sent[0]["pages"].([]interface{})
+	if len(pages) != 2 || pages[0].(map[string]interface{})["display_period"] != "30" {
+		t.Fatalf("Bad pages sent: %#v", pages)
+	}
+	w := pages[0].(map[string]interface{})["widgets"].([]interface{})[0].(map[string]interface{})
+	fields := w["fields"].([]interface{})
+	if w["type"] != "graph" || w["view_mode"] != "1" || fields[1].(map[string]interface{})["type"] != "6" {
+		t.Errorf("Bad graph widget sent: %#v", w)
+	}
+	w = pages[1].(map[string]interface{})["widgets"].([]interface{})[0].(map[string]interface{})
+	if _, ok := w["view_mode"]; ok || len(w["fields"].([]interface{})) != 2 {
+		t.Errorf("Bad problems widget sent: %#v", w)
+	}
+}
@@ -0,0 +1,76 @@
+package zabbix
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationUnits are the time suffixes accepted by Zabbix
+var durationUnits = map[byte]time.Duration{
+	's': time.Second,
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// parseDuration parses a Zabbix time period like "30", "90s", "15m" or "7d".
+// Plain numbers are seconds.
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, fmt.Errorf("Empty duration")
+	}
+	unit := time.Second
+	if u, ok := durationUnits[s[len(s)-1]]; ok {
+		unit = u
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("Invalid duration %q", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+var (
+	// flexiblePeriodRegexp matches the period of a flexible interval, "1-5,09:00-18:00"
+	flexiblePeriodRegexp = regexp.MustCompile(`^[1-7](-[1-7])?,\d{1,2}:\d{2}-\d{1,2}:\d{2}$`)
+	// schedulingRegexp matches a scheduling interval, "wd1-5h9-18"
+	schedulingRegexp = regexp.MustCompile(`^((md|wd|h|m|s)[0-9,\-/]+)+$`)
+)
+
+// parseItemDelay parses an item update interval, "30s;50s/1-5,09:00-18:00;wd1-5h9",
+// returning the base interval and checking the custom intervals following it
+func parseItemDelay(s string) (time.Duration, error) {
+	parts := strings.Split(s, ";")
+	base, err := parseDuration(parts[0])
+	if err != nil {
+		return 0, err
+	}
+	for _, custom := range parts[1:] {
+		if i := strings.Index(custom, "/"); i >= 0 {
+			_, err = parseDuration(custom[:i])
+			if err == nil && !flexiblePeriodRegexp.MatchString(custom[i+1:]) {
+				err = fmt.Errorf("Invalid period %q", custom[i+1:])
+			}
+		} else if !schedulingRegexp.MatchString(custom) {
+			err = fmt.Errorf("Invalid custom interval %q", custom)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return base, nil
+}
+
+// unixTime converts a unix timestamp string as returned by Zabbix, zero time for "0" or empty
+func unixTime(s string) time.Time {
+	clock, _ := strconv.ParseInt(s, 10, 64)
+	if clock == 0 {
+		return time.Time{}
+	}
+	return time.Unix(clock, 0)
+}
@@ -0,0 +1,177 @@
+package zabbix
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type (
+	// EventSource type of the event
+	// see "source" in https://www.zabbix.com/documentation/6.0/manual/api/reference/event/object
+	EventSource int
+
+	// AckAction bitmask of the event.acknowledge actions, values can be or'ed
+	// see "action" in https://www.zabbix.com/documentation/6.0/manual/api/reference/event/acknowledge
+	AckAction int
+)
+
+const (
+	// EventSourceTrigger event created by a trigger
+	EventSourceTrigger EventSource = 0
+	// EventSourceDiscovery event created by a discovery rule
+	EventSourceDiscovery EventSource = 1
+	// EventSourceAutoRegistration event created by active agent auto-registration
+	EventSourceAutoRegistration EventSource = 2
+	// EventSourceInternal internal event
+	EventSourceInternal EventSource = 3
+	// EventSourceService event created on service status update
+	EventSourceService EventSource = 4
+)
+
+const (
+	// AckActionClose close the problem
+	AckActionClose AckAction = 1
+	// AckActionAcknowledge acknowledge the event
+	AckActionAcknowledge AckAction = 2
+	// AckActionAddMessage add a message
+	AckActionAddMessage AckAction = 4
+	// AckActionChangeSeverity change the severity, see EventAcknowledgeWithSeverity
+	AckActionChangeSeverity AckAction = 8
+	// AckActionUnacknowledge unacknowledge the event
+	AckActionUnacknowledge AckAction = 16
+	// AckActionSuppress suppress the event, Zabbix 6.2+
+	AckActionSuppress AckAction = 32
+	// AckActionUnsuppress unsuppress the event, Zabbix 6.2+
+	AckActionUnsuppress AckAction = 64
+)
+
+// Event represent Zabbix event object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/event/object
+type Event struct {
+	EventID      string       `json:"eventid"`
+	Source       EventSource  `json:"source,string"`
+	Object       string       `json:"object"`
+	ObjectID     string       `json:"objectid"`
+	Clock        string       `json:"clock"`
+	NS           string       `json:"ns"`
+	Value        string       `json:"value"`
+	Acknowledged string       `json:"acknowledged"`
+	Severity     SeverityType `json:"severity,string"`
+	Name         string       `json:"name"`
+	REventID     string       `json:"r_eventid"`
+	Tags         Tags         `json:"tags,omitempty"`
+}
+
+// Events is an array of Event
+type Events []Event
+
+// EventGetOptions typed parameters for event.get
+// Events are sorted newest first, by clock and eventid, unless a sort field is set.
+type EventGetOptions struct {
+	GetOptions
+	EventIDs  []string
+	ObjectIDs []string
+	Source    EventSource
+	// Severities of trigger events to return, Zabbix 6.0+
+	Severities []SeverityType
+	// Value returns only problem (1) or recovery (0) events when set
+	Value *int
+	// From and Till bound the events time when set
+	From time.Time
+	Till time.Time
+	// SelectTags fills Tags
+	SelectTags bool
+}
+
+func (o EventGetOptions) params() Params {
+	params := o.GetOptions.withDefaultSort("clock", "eventid").params()
+	params.setIDs("eventids", o.EventIDs)
+	params.setIDs("objectids", o.ObjectIDs)
+	params["source"] = o.Source
+	if len(o.Severities) > 0 {
+		params["severities"] = o.Severities
+	}
+	if o.Value != nil {
+		params["value"] = *o.Value
+	}
+	if !o.From.IsZero() {
+		params["time_from"] = o.From.Unix()
+	}
+	if !o.Till.IsZero() {
+		params["time_till"] = o.Till.Unix()
+	}
+	if o.SelectTags {
+		params["selectTags"] = "extend"
+	}
+	return params
+}
+
+// EventsGet Wrapper for event.get
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/event/get
+func (api *API) EventsGet(opts EventGetOptions) (res Events, err error) {
+	params := opts.params()
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	if err = api.CallWithErrorParse("event.get", params, &res); err == nil {
+		err = opts.truncated(len(res))
+	}
+	return
+}
+
+// eventsWindowLimit is the number of events fetched per call by EventsGetByWindows
+const eventsWindowLimit = 10000
+
+// EventsGetByWindows Gets trigger events between from and till (unix times, inclusive)
+// splitting the range in windows, fn is called with the events of each window in order.
+// Windows holding more than eventsWindowLimit events are fetched in several calls.
+// Stops at the first error of fn or when Config.BaseContext is done.
+func (api *API) EventsGetByWindows(from, till int64, window time.Duration, fn func(Events) error) error {
+	step := int64(window / time.Second)
+	if step < 1 {
+		return fmt.Errorf("Window %s is shorter than a second", window)
+	}
+
+	for start := from; start <= till; start += step {
+		if err := api.ctxErr(); err != nil {
+			return err
+		}
+		end := start + step - 1
+		if end > till {
+			end = till
+		}
+
+		events := Events{}
+		params := Params{
+			"output":    "extend",
+			"source":    EventSourceTrigger,
+			"time_from": start,
+			"time_till": end,
+			"sortfield": "eventid",
+			"sortorder": SortAsc,
+			"limit":     eventsWindowLimit,
+		}
+		for {
+			var page Events
+			if err := api.CallWithErrorParse("event.get", params, &page); err != nil {
+				return err
+			}
+			events = append(events, page...)
+			if len(page) < eventsWindowLimit {
+				break
+			}
+			id := page[len(page)-1].EventID
+			last, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				return fmt.Errorf("unexpected event id %q: %s", id, err)
+			}
+			params["eventid_from"] = last + 1
+		}
+
+		if err := fn(events); err != nil {
+			return err
+		}
+	}
+	return nil
+}
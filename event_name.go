@@ -0,0 +1,112 @@
+package zabbix
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// severityNames are the default names of trigger severities
+var severityNames = map[SeverityType]string{
+	NotClassified: "Not classified",
+	Information:   "Information",
+	Warning:       "Warning",
+	Average:       "Average",
+	High:          "High",
+	Critical:      "Disaster",
+}
+
+// eventNameMacroRegexp matches the built-in macros expanded by PreviewEventName,
+// with the optional index of the item in the expression
+var eventNameMacroRegexp = regexp.MustCompile(`\{(HOST\.HOST|HOST\.NAME|ITEM\.NAME|ITEM\.KEY|ITEM\.VALUE|ITEM\.LASTVALUE|TRIGGER\.NAME|TRIGGER\.SEVERITY)([1-9]?)\}`)
+
+// functionIDRegexp matches the function references of a trigger expression
+var functionIDRegexp = regexp.MustCompile(`\{(\d+)\}`)
+
+// PreviewEventName Renders the event name of the trigger, its description when not set,
+// expanding {HOST.HOST}, {HOST.NAME}, {ITEM.NAME}, {ITEM.KEY}, {ITEM.VALUE}, {ITEM.LASTVALUE},
+// {TRIGGER.NAME} and {TRIGGER.SEVERITY}, numbered like {HOST.NAME2} or not.
+// {ITEM.VALUE} is the last value as there is no event yet. Other macros are kept as is,
+// macros referring to unknown items become *UNKNOWN* like in Zabbix.
+func (api *API) PreviewEventName(triggerID string) (string, error) {
+	triggers, err := api.TriggersGet(Params{
+		"triggerids":      triggerID,
+		"output":          []string{"triggerid", "description", "event_name", "expression", "priority"},
+		"selectFunctions": []string{"functionid", "itemid"},
+		"selectItems":     []string{"itemid", "hostid", "name", "key_", "lastvalue", "units"},
+		"selectHosts":     []string{"hostid", "host", "name"},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(triggers) != 1 {
+		e := ExpectedOneResult(len(triggers))
+		return "", &e
+	}
+	return expandEventName(triggers[0]), nil
+}
+
+// expandEventName expands the built-in macros of the event name of trigger
+func expandEventName(trigger Trigger) string {
+	name := trigger.EventName
+	if name == "" {
+		name = trigger.Description
+	}
+
+	// items are numbered in the order of their functions in the expression
+	itemByFunction := map[string]string{}
+	for _, f := range trigger.Functions {
+		itemByFunction[f.FunctionID] = f.ItemID
+	}
+	itemByID := map[string]Item{}
+	for _, item := range trigger.ContainedItems {
+		itemByID[item.ItemID] = item
+	}
+	hostByID := map[string]Host{}
+	for _, host := range trigger.ParentHosts {
+		hostByID[host.HostID] = host
+	}
+	items := []Item{}
+	for _, m := range functionIDRegexp.FindAllStringSubmatch(trigger.Expression, -1) {
+		if item, ok := itemByID[itemByFunction[m[1]]]; ok {
+			items = append(items, item)
+		}
+	}
+
+	return eventNameMacroRegexp.ReplaceAllStringFunc(name, func(macro string) string {
+		m := eventNameMacroRegexp.FindStringSubmatch(macro)
+		switch m[1] {
+		case "TRIGGER.NAME":
+			return trigger.Description
+		case "TRIGGER.SEVERITY":
+			return severityNames[trigger.Priority]
+		}
+
+		n := 1
+		if m[2] != "" {
+			n, _ = strconv.Atoi(m[2])
+		}
+		if n > len(items) {
+			return "*UNKNOWN*"
+		}
+		item := items[n-1]
+		host := hostByID[item.HostID]
+		switch m[1] {
+		case "HOST.HOST":
+			return host.Host
+		case "HOST.NAME":
+			return host.Name
+		case "ITEM.NAME":
+			return item.Name
+		case "ITEM.KEY":
+			return item.Key
+		}
+		if item.LastValue == "" {
+			return "*UNKNOWN*"
+		}
+		if item.Units != "" {
+			return fmt.Sprintf("%s %s", item.LastValue, item.Units)
+		}
+		return item.LastValue
+	})
+}
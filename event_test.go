@@ -0,0 +1,189 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestEventsGetSeverities(t *testing.T) {
+	var sent json.RawMessage
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		sent = params
+		return []map[string]string{{"eventid": "1", "source": "0", "severity": "4", "value": "1"}}, nil
+	})
+
+	value := 1
+	events, err := api.EventsGet(zapi.EventGetOptions{
+		Source:     zapi.EventSourceTrigger,
+		Severities: []zapi.SeverityType{zapi.High, zapi.Critical},
+		Value:      &value,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"severities":[4,5]`, `"source":0`, `"value":1`} {
+		if !strings.Contains(string(sent), s) {
+			t.Errorf("Expected %s in %s", s, sent)
+		}
+	}
+	if len(events) != 1 || events[0].Severity != zapi.High {
+		t.Errorf("Bad events: %#v", events)
+	}
+}
+
+func TestEventsGetByWindows(t *testing.T) {
+	type window struct{ from, till int64 }
+	windows := []window{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var p struct {
+			From int64 `json:"time_from"`
+			Till int64 `json:"time_till"`
+		}
+		json.Unmarshal(params, &p)
+		windows = append(windows, window{p.From, p.Till})
+		return []map[string]string{{"eventid": "1", "source": "0", "clock": "0"}}, nil
+	})
+
+	calls := 0
+	err := api.EventsGetByWindows(1000, 1000+3*86400+60, 24*time.Hour, func(events zapi.Events) error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 4 || calls != 4 {
+		t.Fatalf("Expected 4 windows, got %v", windows)
+	}
+	next := int64(1000)
+	for _, w := range windows {
+		if w.from != next || w.till < w.from {
+			t.Errorf("Windows do not cover the range: %v", windows)
+		}
+		next = w.till + 1
+	}
+	if next != 1000+3*86400+61 {
+		t.Errorf("Windows do not reach the end: %v", windows)
+	}
+}
+
+func TestEventsGetByWindowsBadID(t *testing.T) {
+	calls := 0
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		calls++
+		var p struct {
+			Limit int `json:"limit"`
+		}
+		json.Unmarshal(params, &p)
+		// a full page ending with an id not a number
+		page := make([]map[string]string, p.Limit)
+		for i := range page {
+			page[i] = map[string]string{"eventid": "1", "source": "0", "clock": "0"}
+		}
+		page[len(page)-1]["eventid"] = "last"
+		return page, nil
+	})
+
+	err := api.EventsGetByWindows(1000, 2000, time.Hour, func(events zapi.Events) error {
+		t.Error("Events of the window passed on")
+		return nil
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("Expected error for event id, got %v after %d calls", err, calls)
+	}
+}
+
+func TestEventsAndProblemsDefaultSort(t *testing.T) {
+	var sent map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		sent = nil
+		json.Unmarshal(params, &sent)
+		return []interface{}{}, nil
+	})
+
+	tests := []struct {
+		get   func() error
+		field interface{}
+		order interface{}
+	}{
+		{func() error { _, err := api.EventsGet(zapi.EventGetOptions{}); return err }, []interface{}{"clock", "eventid"}, "DESC"},
+		{func() error {
+			_, err := api.EventsGet(zapi.EventGetOptions{GetOptions: zapi.GetOptions{SortField: "eventid", SortOrder: zapi.SortAsc}})
+			return err
+		}, "eventid", "ASC"},
+		{func() error { _, err := api.ProblemsGetWithOptions(zapi.ProblemGetOptions{}); return err }, []interface{}{"eventid"}, "DESC"},
+		{func() error {
+			_, err := api.ProblemsGetWithOptions(zapi.ProblemGetOptions{GetOptions: zapi.GetOptions{SortField: "eventid"}})
+			return err
+		}, "eventid", nil},
+	}
+	for i, test := range tests {
+		if err := test.get(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(sent["sortfield"], test.field) || sent["sortorder"] != test.order {
+			t.Errorf("Test %d: bad sort %v %v", i, sent["sortfield"], sent["sortorder"])
+		}
+	}
+}
+
+func TestEventsGetFilters(t *testing.T) {
+	var sent map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return []interface{}{map[string]interface{}{
+			"eventid": "7", "source": "0", "objectid": "13", "value": "1",
+			"tags": []map[string]string{{"tag": "scope", "value": "availability"}},
+		}}, nil
+	})
+
+	from, till := time.Unix(1700000000, 0), time.Unix(1700003600, 0)
+	events, err := api.EventsGet(zapi.EventGetOptions{ObjectIDs: []string{"13"}, From: from, Till: till, SelectTags: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent["time_from"] != float64(from.Unix()) || sent["time_till"] != float64(till.Unix()) || sent["selectTags"] != "extend" {
+		t.Errorf("Bad params: %v", sent)
+	}
+	if ids, _ := sent["objectids"].([]interface{}); len(ids) != 1 || ids[0] != "13" {
+		t.Errorf("Bad objectids: %v", sent["objectids"])
+	}
+	if len(events) != 1 || events[0].ObjectID != "13" || !events[0].Tags.Has("scope", "availability") {
+		t.Errorf("Bad events: %#v", events)
+	}
+}
+
+func TestEventAcknowledge(t *testing.T) {
+	var sent map[string]interface{}
+	calls := 0
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		calls++
+		sent = nil
+		json.Unmarshal(params, &sent)
+		return map[string][]int{"eventids": {1, 2}}, nil
+	})
+
+	err := api.EventAcknowledge([]string{"1", "2"}, zapi.AckActionAcknowledge|zapi.AckActionClose, "fixed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent["action"] != float64(7) || sent["message"] != "fixed" {
+		t.Errorf("Bad params: %v", sent)
+	}
+
+	if err = api.EventAcknowledge([]string{"1"}, zapi.AckActionChangeSeverity, ""); err == nil || calls != 1 {
+		t.Error("Expected severity change refused without a severity")
+	}
+
+	if err = api.EventAcknowledgeWithSeverity([]string{"1"}, zapi.AckActionAcknowledge, "", zapi.High); err != nil {
+		t.Fatal(err)
+	}
+	if sent["action"] != float64(10) || sent["severity"] != float64(zapi.High) {
+		t.Errorf("Bad params: %v", sent)
+	}
+}
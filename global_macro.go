@@ -0,0 +1,134 @@
+package zabbix
+
+import "encoding/json"
+
+// GlobalMacro represent Zabbix global macro object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/usermacro/object#global-macro
+type GlobalMacro struct {
+	GlobalMacroID string `json:"globalmacroid,omitempty"`
+	MacroName     string `json:"macro"`
+	// Value is not returned for secret macros
+	Value       string    `json:"value,omitempty"`
+	Type        MacroType `json:"type,string"`
+	Description string    `json:"description,omitempty"`
+
+	// untyped leaves Type out, for servers before Zabbix 5.0
+	untyped bool
+}
+
+// MarshalJSON encodes the macro, without its type for servers before Zabbix 5.0
+func (m GlobalMacro) MarshalJSON() ([]byte, error) {
+	type macro GlobalMacro
+	if !m.untyped {
+		return json.Marshal(macro(m))
+	}
+	return json.Marshal(struct {
+		macro
+		Type *MacroType `json:"type,omitempty"`
+	}{macro: macro(m)})
+}
+
+// GlobalMacros is an array of GlobalMacro
+type GlobalMacros []GlobalMacro
+
+// prepGlobalMacroTypes leaves the type out of the macros on servers before Zabbix 5.0,
+// macros are left as they are when the version is unknown
+func (api *API) prepGlobalMacroTypes(macros GlobalMacros) {
+	if len(macros) == 0 {
+		return
+	}
+	untyped := api.untypedMacros()
+	for i := range macros {
+		macros[i].untyped = untyped
+	}
+}
+
+// validateGlobalMacros checks the macros before they are sent
+func validateGlobalMacros(macros GlobalMacros) error {
+	for _, m := range macros {
+		if err := validateMacroValue(m.MacroName, m.Value, m.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GlobalMacrosGet Wrapper for usermacro.get returning global macros
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/usermacro/get
+func (api *API) GlobalMacrosGet(params Params) (res GlobalMacros, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	params["globalmacro"] = true
+	err = api.CallWithErrorParse("usermacro.get", params, &res)
+	return
+}
+
+// GlobalMacrosCreate Wrapper for usermacro.createglobal
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/usermacro/createglobal
+func (api *API) GlobalMacrosCreate(macros GlobalMacros) (err error) {
+	if err = validateGlobalMacros(macros); err != nil {
+		return
+	}
+	api.prepGlobalMacroTypes(macros)
+	response, err := api.CallWithError("usermacro.createglobal", macros)
+	if err != nil {
+		return
+	}
+
+	macroids, err := resultIDs(response, "globalmacroids")
+	if err != nil {
+		return
+	}
+	if len(macroids) != len(macros) {
+		return &ExpectedMore{len(macros), len(macroids)}
+	}
+	for i, id := range macroids {
+		macros[i].GlobalMacroID = id
+	}
+	return
+}
+
+// GlobalMacrosUpdate Wrapper for usermacro.updateglobal
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/usermacro/updateglobal
+func (api *API) GlobalMacrosUpdate(macros GlobalMacros) (err error) {
+	if err = validateGlobalMacros(macros); err != nil {
+		return
+	}
+	api.prepGlobalMacroTypes(macros)
+	_, err = api.CallWithError("usermacro.updateglobal", macros)
+	return
+}
+
+// GlobalMacrosDeleteByIDs Wrapper for usermacro.deleteglobal
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/usermacro/deleteglobal
+func (api *API) GlobalMacrosDeleteByIDs(ids []string) (err error) {
+	response, err := api.CallWithError("usermacro.deleteglobal", ids)
+	if err != nil {
+		return
+	}
+
+	macroids, err := resultIDs(response, "globalmacroids")
+	if err == nil && len(ids) != len(macroids) {
+		err = &ExpectedMore{len(ids), len(macroids)}
+	}
+	return
+}
+
+// GlobalMacrosDelete Wrapper for usermacro.deleteglobal
+// Cleans GlobalMacroID in all macro elements if call succeed.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/usermacro/deleteglobal
+func (api *API) GlobalMacrosDelete(macros GlobalMacros) (err error) {
+	ids := make([]string, len(macros))
+	for i, macro := range macros {
+		ids[i] = macro.GlobalMacroID
+	}
+
+	err = api.GlobalMacrosDeleteByIDs(ids)
+	if err == nil {
+		for i := range macros {
+			macros[i].GlobalMacroID = ""
+		}
+	}
+	return
+}
@@ -0,0 +1,160 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestGlobalMacros(t *testing.T) {
+	var sent []map[string]interface{}
+	methods := []string{}
+	api := getMockAPI(t, zapi.Config{Version: 50000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		methods = append(methods, method)
+		switch method {
+		case "usermacro.get":
+			var p map[string]interface{}
+			json.Unmarshal(params, &p)
+			if p["globalmacro"] != true {
+				t.Errorf("Global macros not asked: %s", params)
+			}
+			return []map[string]string{
+				{"globalmacroid": "1", "macro": "{$SNMP_COMMUNITY}", "value": "public", "type": "0"},
+				{"globalmacroid": "2", "macro": "{$DB.PASSWORD}", "type": "1", "description": "Monitoring user"},
+			}, nil
+		case "usermacro.createglobal":
+			json.Unmarshal(params, &sent)
+			return map[string][]string{"globalmacroids": {"11", "12"}}, nil
+		case "usermacro.deleteglobal":
+			return map[string][]string{"globalmacroids": {"11", "12"}}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	macros, err := api.GlobalMacrosGet(zapi.Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(macros) != 2 || macros[1].Type != zapi.MacroTypeSecret || macros[1].Value != "" || macros[1].Description != "Monitoring user" {
+		t.Errorf("Bad global macros: %#v", macros)
+	}
+
+	macros = zapi.GlobalMacros{
+		{MacroName: "{$PROXY}", Value: "proxy.example.com"},
+		{MacroName: "{$API.KEY}", Value: "secret/zabbix:key", Type: zapi.MacroTypeVault},
+	}
+	if err = api.GlobalMacrosCreate(macros); err != nil {
+		t.Fatal(err)
+	}
+	if macros[0].GlobalMacroID != "11" || macros[1].GlobalMacroID != "12" {
+		t.Errorf("Ids not backfilled: %#v", macros)
+	}
+	if len(sent) != 2 || sent[0]["type"] != "0" || sent[1]["type"] != "2" {
+		t.Errorf("Bad macros sent: %#v", sent)
+	}
+
+	if err = api.GlobalMacrosDelete(macros); err != nil {
+		t.Fatal(err)
+	}
+	if macros[0].GlobalMacroID != "" || len(methods) != 3 {
+		t.Errorf("Bad delete: %#v %v", macros, methods)
+	}
+}
+
+func TestMacroTypes(t *testing.T) {
+	var sent []map[string]interface{}
+	var methods []string
+	api := getMockAPI(t, zapi.Config{Version: 50000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method != "usermacro.create" && method != "usermacro.update" {
+			t.Fatalf("Unexpected method %s", method)
+		}
+		methods = append(methods, method)
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"hostmacroids": {"21", "22"}}, nil
+	})
+
+	macros := zapi.Macros{
+		{HostID: "10084", MacroName: "{$USER}", Value: "zabbix"},
+		{HostID: "10084", MacroName: "{$PASSWORD}", Value: "s3cret", Type: zapi.MacroTypeSecret},
+	}
+	if err := api.MacrosCreate(macros); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 || sent[0]["type"] != "0" || sent[1]["type"] != "1" {
+		t.Errorf("Bad macro types sent: %#v", sent)
+	}
+
+	sent = nil
+	update := zapi.Macros{{MacroID: "22", HostID: "10084", MacroName: "{$PASSWORD}", Value: "visible"}}
+	if err := api.MacrosUpdate(update); err != nil {
+		t.Fatal(err)
+	}
+	if methods[len(methods)-1] != "usermacro.update" || len(sent) != 1 || sent[0]["hostmacroid"] != "22" ||
+		sent[0]["type"] != "0" || sent[0]["hostid"] != nil {
+		t.Errorf("Bad macro update sent: %v %#v", methods, sent)
+	}
+
+	sent = nil
+	vault := zapi.Macros{{HostID: "10084", MacroName: "{$TOKEN}", Type: zapi.MacroTypeVault}}
+	if err := api.MacrosCreate(vault); err == nil || sent != nil {
+		t.Error("Expected error for Vault macro without path")
+	}
+	global := zapi.GlobalMacros{{MacroName: "{$TOKEN}", Value: " ", Type: zapi.MacroTypeVault}}
+	if err := api.GlobalMacrosCreate(global); err == nil {
+		t.Error("Expected error for global Vault macro without path")
+	}
+}
+
+func TestGlobalMacrosReadOnly(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{Version: 50000, ReadOnly: true}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	macros := zapi.GlobalMacros{{GlobalMacroID: "1", MacroName: "{$PROXY}", Value: "proxy.example.com"}}
+	if err := api.GlobalMacrosCreate(macros); err != zapi.ErrReadOnly {
+		t.Errorf("Create: expected ErrReadOnly, got %v", err)
+	}
+	if err := api.GlobalMacrosUpdate(macros); err != zapi.ErrReadOnly {
+		t.Errorf("Update: expected ErrReadOnly, got %v", err)
+	}
+	if err := api.GlobalMacrosDelete(macros); err != zapi.ErrReadOnly {
+		t.Errorf("Delete: expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestMacroTypesPre50(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{Version: 40000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"hostids": {"10084"}, "hostmacroids": {"21"}}, nil
+	})
+
+	hosts := zapi.Hosts{{Host: "web01", UserMacros: zapi.Macros{{MacroName: "{$USER}", Value: "zabbix"}}}}
+	if err := api.HostsCreate(hosts); err != nil {
+		t.Fatal(err)
+	}
+	macros, _ := sent[0]["macros"].([]interface{})
+	if len(macros) != 1 {
+		t.Fatalf("Bad host sent: %#v", sent)
+	}
+	if _, ok := macros[0].(map[string]interface{})["type"]; ok {
+		t.Errorf("Macro type sent before 5.0: %#v", macros[0])
+	}
+
+	if err := api.MacrosCreate(zapi.Macros{{HostID: "10084", MacroName: "{$USER}", Value: "zabbix"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sent[0]["type"]; ok {
+		t.Errorf("Macro type sent before 5.0: %#v", sent[0])
+	}
+	global := zapi.GlobalMacros{{MacroName: "{$PROXY}", Value: "proxy.example.com"}}
+	if err := api.GlobalMacrosUpdate(global); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sent[0]["type"]; ok || sent[0]["macro"] != "{$PROXY}" {
+		t.Errorf("Global macro type sent before 5.0: %#v", sent[0])
+	}
+}
@@ -1,5 +1,10 @@
 package zabbix
 
+import (
+	"fmt"
+	"strconv"
+)
+
 type (
 	GraphType string
 	GraphAxis string
@@ -58,6 +63,7 @@ type GraphItems []GraphItem
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/graph/object
 type Graph struct {
 	GraphID        string    `json:"graphid,omitempty"`
+	UUID           string    `json:"uuid,omitempty"`
 	Name           string    `json:"name"`
 	Height         string    `json:"height"`
 	Width          string    `json:"width"`
@@ -66,6 +72,7 @@ type Graph struct {
 	PercentRight   string    `json:"percent_right,omitempty"`
 	Show3d         string    `json:"show_3d,omitempty"`
 	ShowLegend     string    `json:"show_legend,omitempty"`
+	ShowTriggers   string    `json:"show_triggers,omitempty"`
 	ShowWorkPeriod string    `json:"show_work_period,omitempty"`
 	YMax           string    `json:"yaxismax,omitempty"`
 	YMaxItemId     string    `json:"ymax_itemid,omitempty"`
@@ -75,11 +82,47 @@ type Graph struct {
 	YMinType       GraphAxis `json:"ymin_type,omitempty"`
 
 	GraphItems GraphItems `json:"gitems,omitempty"`
+	// Hosts that the graph belongs to, read only
+	ParentHosts Hosts `json:"hosts,omitempty"`
 }
 
 // HostGroups is an array of HostGroup
 type Graphs []Graph
 
+// validateNewGraphs checks the graphs before they are created, they need items
+func validateNewGraphs(graphs Graphs) error {
+	for _, g := range graphs {
+		if len(g.GraphItems) == 0 {
+			return fmt.Errorf("Graph %s: at least one graph item is required", g.Name)
+		}
+	}
+	return validateGraphs(graphs)
+}
+
+// validateGraphs checks the graphs before they are sent
+func validateGraphs(graphs Graphs) error {
+	for _, g := range graphs {
+		if g.YMinType == GraphAxisItem && g.YMinItemId == "" {
+			return fmt.Errorf("Graph %s: y axis min of type item requires YMinItemId", g.Name)
+		}
+		if g.YMaxType == GraphAxisItem && g.YMaxItemId == "" {
+			return fmt.Errorf("Graph %s: y axis max of type item requires YMaxItemId", g.Name)
+		}
+		// percentile lines, "0" for none, are drawn on normal graphs only
+		for _, percent := range []string{g.PercentLeft, g.PercentRight} {
+			if percent == "" {
+				continue
+			}
+			if p, err := strconv.ParseFloat(percent, 64); err != nil || p < 0 || p > 100 {
+				return fmt.Errorf("Graph %s: invalid percentile %q", g.Name, percent)
+			} else if p > 0 && g.Type != "" && g.Type != GraphNormal {
+				return fmt.Errorf("Graph %s: percentile lines need a normal graph", g.Name)
+			}
+		}
+	}
+	return nil
+}
+
 // GraphsGet Wrapper for graph.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/graph/get
 func (api *API) GraphsGet(params Params) (res Graphs, err error) {
@@ -89,6 +132,53 @@ func (api *API) GraphsGet(params Params) (res Graphs, err error) {
 	err = api.CallWithErrorParse("graph.get", params, &res)
 	return
 }
+
+// GraphGetOptions typed parameters for graph.get
+type GraphGetOptions struct {
+	GetOptions
+	GraphIDs    []string
+	HostIDs     []string
+	TemplateIDs []string
+	ItemIDs     []string
+	// SelectGraphItems fills GraphItems
+	SelectGraphItems bool
+	// SelectHosts fills ParentHosts
+	SelectHosts bool
+}
+
+func (o GraphGetOptions) params() Params {
+	params := o.GetOptions.params()
+	params.setIDs("graphids", o.GraphIDs)
+	params.setIDs("hostids", o.HostIDs)
+	params.setIDs("templateids", o.TemplateIDs)
+	params.setIDs("itemids", o.ItemIDs)
+	if o.SelectGraphItems {
+		params["selectGraphItems"] = "extend"
+	}
+	if o.SelectHosts {
+		params["selectHosts"] = []string{"hostid", "host", "name"}
+	}
+	return params
+}
+
+// GraphsGetWithOptions Wrapper for graph.get using typed options
+func (api *API) GraphsGetWithOptions(opts GraphGetOptions) (res Graphs, err error) {
+	if res, err = api.GraphsGet(opts.params()); err == nil {
+		err = opts.truncated(len(res))
+	}
+	return
+}
+
+// GraphsGetByHostIDs Gets graphs of the hosts along with their graph items.
+func (api *API) GraphsGetByHostIDs(ids []string) (res Graphs, err error) {
+	return api.GraphsGetWithOptions(GraphGetOptions{HostIDs: ids, SelectGraphItems: true})
+}
+
+// GraphsGetByTemplateIDs Gets graphs of the templates along with their graph items.
+func (api *API) GraphsGetByTemplateIDs(ids []string) (res Graphs, err error) {
+	return api.GraphsGetWithOptions(GraphGetOptions{TemplateIDs: ids, SelectGraphItems: true})
+}
+
 func (api *API) GraphProtosGet(params Params) (res Graphs, err error) {
 	if _, present := params["output"]; !present {
 		params["output"] = "extend"
@@ -99,59 +189,68 @@ func (api *API) GraphProtosGet(params Params) (res Graphs, err error) {
 
 // GraphGetByID Gets host group by Id only if there is exactly 1 matching host group.
 func (api *API) GraphGetByID(id string) (res *Graph, err error) {
-	groups, err := api.GraphsGet(Params{"graphids": id})
-	if err != nil {
-		return
-	}
-
-	if len(groups) == 1 {
-		res = &groups[0]
-	} else {
-		e := ExpectedOneResult(len(groups))
-		err = &e
+	res = &Graph{}
+	if err = api.GetOne("graph.get", Params{"graphids": id}, res); err != nil {
+		res = nil
 	}
 	return
 }
 func (api *API) GraphProtoGetByID(id string) (res *Graph, err error) {
-	groups, err := api.GraphProtosGet(Params{"graphids": id})
-	if err != nil {
-		return
-	}
-
-	if len(groups) == 1 {
-		res = &groups[0]
-	} else {
-		e := ExpectedOneResult(len(groups))
-		err = &e
+	res = &Graph{}
+	if err = api.GetOne("graphprototype.get", Params{"graphids": id}, res); err != nil {
+		res = nil
 	}
 	return
 }
 
 // GraphsCreate Wrapper for graph.create
+// Template graphs missing an UUID get one on Zabbix 6.0+.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/graph/create
 func (api *API) GraphsCreate(hostGroups Graphs) (err error) {
+	if err = validateNewGraphs(hostGroups); err != nil {
+		return
+	}
+	if err = api.fillGraphUUIDs(hostGroups, false); err != nil {
+		return
+	}
 	response, err := api.CallWithError("graph.create", hostGroups)
 	if err != nil {
 		return
 	}
 
-	result := response.Result.(map[string]interface{})
-	groupids := result["graphids"].([]interface{})
+	groupids, err := resultIDs(response, "graphids")
+	if err != nil {
+		return
+	}
+	if len(groupids) != len(hostGroups) {
+		return &ExpectedMore{len(hostGroups), len(groupids)}
+	}
 	for i, id := range groupids {
-		hostGroups[i].GraphID = id.(string)
+		hostGroups[i].GraphID = id
 	}
 	return
 }
 func (api *API) GraphProtosCreate(hostGroups Graphs) (err error) {
+	if err = validateNewGraphs(hostGroups); err != nil {
+		return
+	}
+	if err = api.fillGraphUUIDs(hostGroups, true); err != nil {
+		return
+	}
 	response, err := api.CallWithError("graphprototype.create", hostGroups)
 	if err != nil {
 		return
 	}
 
-	result := response.Result.(map[string]interface{})
-	groupids := result["graphids"].([]interface{})
+	groupids, err := resultIDs(response, "graphids")
+	if err != nil {
+		return
+	}
+	if len(groupids) != len(hostGroups) {
+		return &ExpectedMore{len(hostGroups), len(groupids)}
+	}
 	for i, id := range groupids {
-		hostGroups[i].GraphID = id.(string)
+		hostGroups[i].GraphID = id
 	}
 	return
 }
@@ -159,10 +258,16 @@ func (api *API) GraphProtosCreate(hostGroups Graphs) (err error) {
 // GraphsUpdate Wrapper for graph.update
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/graph/update
 func (api *API) GraphsUpdate(hostGroups Graphs) (err error) {
+	if err = validateGraphs(hostGroups); err != nil {
+		return
+	}
 	_, err = api.CallWithError("graph.update", hostGroups)
 	return
 }
 func (api *API) GraphProtosUpdate(hostGroups Graphs) (err error) {
+	if err = validateGraphs(hostGroups); err != nil {
+		return
+	}
 	_, err = api.CallWithError("graphprototype.update", hostGroups)
 	return
 }
@@ -0,0 +1,110 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestGraphsGetByHostIDs(t *testing.T) {
+	var sent map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return []map[string]interface{}{{
+			"graphid": "7",
+			"name":    "CPU",
+			"gitems":  []map[string]string{{"gitemid": "1", "itemid": "100", "color": "00AA00"}},
+		}}, nil
+	})
+
+	graphs, err := api.GraphsGetByHostIDs([]string{"42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent["selectGraphItems"] != "extend" || sent["output"] != "extend" {
+		t.Errorf("Bad params: %#v", sent)
+	}
+	if len(graphs) != 1 || len(graphs[0].GraphItems) != 1 || graphs[0].GraphItems[0].ItemID != "100" {
+		t.Errorf("Bad graphs: %#v", graphs)
+	}
+}
+
+func TestGraphsCreateItemAxis(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"graphids": {"7"}}, nil
+	})
+
+	graphs := zapi.Graphs{{
+		Name:     "Disk usage",
+		Height:   "200",
+		Width:    "900",
+		YMaxType: zapi.GraphAxisItem,
+	}}
+	if err := api.GraphsCreate(graphs); err == nil || sent != nil {
+		t.Error("Expected error for graph without items")
+	}
+
+	graphs[0].GraphItems = zapi.GraphItems{{ItemID: "100", Color: "00AA00"}}
+	if err := api.GraphsCreate(graphs); err == nil {
+		t.Error("Expected error for item y axis without item")
+	}
+
+	graphs[0].YMaxItemId = "101"
+	if err := api.GraphsCreate(graphs); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0]["ymax_type"] != "2" || sent[0]["ymax_itemid"] != "101" || graphs[0].GraphID != "7" {
+		t.Errorf("Bad graph sent: %#v", sent)
+	}
+	if gitems, _ := sent[0]["gitems"].([]interface{}); len(gitems) != 1 {
+		t.Errorf("Bad graph items sent: %#v", sent[0]["gitems"])
+	}
+}
+
+func TestGraphsCreateDisplayFlags(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"graphids": {"8"}}, nil
+	})
+
+	graphs := zapi.Graphs{{
+		Name:           "Network traffic",
+		Height:         "200",
+		Width:          "900",
+		Type:           zapi.GraphNormal,
+		PercentRight:   "95",
+		ShowLegend:     "1",
+		ShowTriggers:   "0",
+		ShowWorkPeriod: "0",
+		Show3d:         "0",
+		YMinType:       zapi.GraphAxisFixed,
+		YMin:           "0",
+		GraphItems:     zapi.GraphItems{{ItemID: "100", Color: "1A7C11"}},
+	}}
+	if err := api.GraphsCreate(graphs); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"percent_right": "95", "show_legend": "1", "show_triggers": "0",
+		"show_work_period": "0", "show_3d": "0", "ymin_type": "1", "yaxismin": "0",
+	}
+	for k, v := range expected {
+		if len(sent) != 1 || sent[0][k] != v {
+			t.Errorf("Expected %s %q, sent %#v", k, v, sent)
+		}
+	}
+
+	sent = nil
+	graphs[0].Type, graphs[0].GraphID = zapi.GraphPie, ""
+	if err := api.GraphsCreate(graphs); err == nil || sent != nil {
+		t.Error("Expected error for percentile line on a pie graph")
+	}
+	graphs[0].Type, graphs[0].PercentRight = zapi.GraphNormal, "120"
+	if err := api.GraphsCreate(graphs); err == nil || sent != nil {
+		t.Error("Expected error for percentile over 100")
+	}
+}
@@ -0,0 +1,148 @@
+package zabbix
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// HistoryRecord represent Zabbix history object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/history/object
+type HistoryRecord struct {
+	ItemID string `json:"itemid"`
+	Clock  string `json:"clock"`
+	Value  string `json:"value"`
+	NS     string `json:"ns"`
+}
+
+// HistoryRecords is an array of HistoryRecord
+type HistoryRecords []HistoryRecord
+
+// PreciseTime returns the time of the record including its nanoseconds
+func (r HistoryRecord) PreciseTime() time.Time {
+	clock, _ := strconv.ParseInt(r.Clock, 10, 64)
+	ns, _ := strconv.ParseInt(r.NS, 10, 64)
+	return time.Unix(clock, ns)
+}
+
+// HistoryGet Wrapper for history.get
+// The "history" param selects the value type, float by default.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/history/get
+func (api *API) HistoryGet(params Params) (res HistoryRecords, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("history.get", params, &res)
+	return
+}
+
+// ItemLastValue Gets the latest history record of the item, nil if it has none.
+// The value type of the item is taken from the ItemValueType cache when known.
+func (api *API) ItemLastValue(itemID string) (res *HistoryRecord, err error) {
+	valueType, err := api.ItemValueType(itemID)
+	if err != nil {
+		return
+	}
+	records, err := api.HistoryGet(Params{
+		"history":   valueType,
+		"itemids":   itemID,
+		"sortfield": "clock",
+		"sortorder": SortDesc,
+		"limit":     1,
+	})
+	if err != nil || len(records) == 0 {
+		return
+	}
+	return &records[0], nil
+}
+
+// HistoryData is a value sent by HistoryPush, identified by ItemID or by Host and Key
+// https://www.zabbix.com/documentation/7.0/manual/api/reference/history/push
+type HistoryData struct {
+	ItemID string `json:"itemid,omitempty"`
+	Host   string `json:"host,omitempty"`
+	Key    string `json:"key,omitempty"`
+	Value  string `json:"value"`
+	// Clock and NS default to the time the server receives the value, NS needs Clock
+	Clock int64 `json:"clock,omitempty"`
+	NS    int   `json:"ns,omitempty"`
+}
+
+// NewHistoryData Builds the value of the item at t, keeping its nanoseconds
+func NewHistoryData(itemID, value string, t time.Time) HistoryData {
+	return HistoryData{ItemID: itemID, Value: value, Clock: t.Unix(), NS: t.Nanosecond()}
+}
+
+// HistoryPushError is returned by HistoryPush when the server refused some values
+type HistoryPushError struct {
+	// Errors by index of the refused values
+	Errors map[int]string
+}
+
+func (e *HistoryPushError) Error() string {
+	return fmt.Sprintf("%d values refused by history.push: %v", len(e.Errors), e.Errors)
+}
+
+// HistoryPush Wrapper for history.push, Zabbix 7.0+
+// https://www.zabbix.com/documentation/7.0/manual/api/reference/history/push
+func (api *API) HistoryPush(data []HistoryData) (err error) {
+	for i, d := range data {
+		if d.NS < 0 || d.NS > 999999999 || (d.NS != 0 && d.Clock == 0) {
+			return fmt.Errorf("Bad ns %d of value %d, ns is 0-999999999 and needs clock", d.NS, i)
+		}
+	}
+
+	var res struct {
+		Data []struct {
+			ItemID string `json:"itemid"`
+			Error  string `json:"error"`
+		} `json:"data"`
+	}
+	if err = api.CallWithErrorParse("history.push", data, &res); err != nil {
+		return
+	}
+	refused := map[int]string{}
+	for i, d := range res.Data {
+		if d.Error != "" {
+			refused[i] = d.Error
+		}
+	}
+	if len(refused) > 0 {
+		return &HistoryPushError{Errors: refused}
+	}
+	return
+}
+
+// lastValueWindow is how far back fillLastValues looks for values
+const lastValueWindow = 24 * time.Hour
+
+// fillLastValues sets LastValue, LastClock and PrevValue of the items from history, one
+// call per item asking for its two latest values. Values older than lastValueWindow are not found.
+func (api *API) fillLastValues(items Items) error {
+	for i, item := range items {
+		if err := api.ctxErr(); err != nil {
+			return err
+		}
+		records, err := api.HistoryGet(Params{
+			"history":   item.ValueType,
+			"itemids":   item.ItemID,
+			"time_from": time.Now().Add(-lastValueWindow).Unix(),
+			"sortfield": "clock",
+			"sortorder": SortDesc,
+			"limit":     2,
+		})
+		if err != nil {
+			return err
+		}
+
+		items[i].LastClock = "0"
+		if len(records) > 0 {
+			items[i].LastValue = records[0].Value
+			items[i].LastClock = records[0].Clock
+		}
+		if len(records) > 1 {
+			items[i].PrevValue = records[1].Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestHistoryPushPreciseTime(t *testing.T) {
+	var stored []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "history.push":
+			json.Unmarshal(params, &stored)
+			return map[string]interface{}{"response": "success", "data": []map[string]string{{"itemid": "10600"}}}, nil
+		case "history.get":
+			res := []map[string]string{}
+			for _, s := range stored {
+				res = append(res, map[string]string{
+					"itemid": s["itemid"].(string),
+					"clock":  strconv.FormatFloat(s["clock"].(float64), 'f', -1, 64),
+					"ns":     strconv.FormatFloat(s["ns"].(float64), 'f', -1, 64),
+					"value":  s["value"].(string),
+				})
+			}
+			return res, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	at := time.Unix(1700000000, 123456789)
+	if err := api.HistoryPush([]zapi.HistoryData{zapi.NewHistoryData("10600", "42", at)}); err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 || stored[0]["clock"] != float64(1700000000) || stored[0]["ns"] != float64(123456789) {
+		t.Fatalf("Bad pushed values: %#v", stored)
+	}
+
+	records, err := api.HistoryGet(zapi.Params{"itemids": "10600"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || !records[0].PreciseTime().Equal(at) {
+		t.Errorf("Nanoseconds lost: %#v", records)
+	}
+}
+
+func TestHistoryPushErrors(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return map[string]interface{}{"response": "failed", "data": []map[string]string{
+			{"itemid": "10600"},
+			{"error": "Item is disabled."},
+		}}, nil
+	})
+
+	if err := api.HistoryPush([]zapi.HistoryData{{ItemID: "1", Value: "1", NS: 5}}); err == nil {
+		t.Error("Expected error for ns without clock")
+	}
+	err := api.HistoryPush([]zapi.HistoryData{{ItemID: "10600", Value: "1"}, {ItemID: "10601", Value: "2"}})
+	pushErr, ok := err.(*zapi.HistoryPushError)
+	if !ok || len(pushErr.Errors) != 1 || pushErr.Errors[1] != "Item is disabled." {
+		t.Errorf("Bad error: %#v", err)
+	}
+}
@@ -1,6 +1,12 @@
 package zabbix
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
 
 type (
 	// AvailableType (readonly) Availability of Zabbix agent
@@ -11,7 +17,21 @@ type (
 	// see "status" in:	https://www.zabbix.com/documentation/3.2/manual/api/reference/host/object
 	StatusType int
 
+	// InventoryMode Host inventory population mode
+	// see "inventory_mode" in: https://www.zabbix.com/documentation/6.0/manual/api/reference/host/object
 	InventoryMode int
+
+	// TLSConnect Connections to the host
+	// see "tls_connect" in: https://www.zabbix.com/documentation/6.0/manual/api/reference/host/object
+	TLSConnect int
+
+	// TLSAccept Connections from the host, bitmask of the accepted types
+	// see "tls_accept" in: https://www.zabbix.com/documentation/6.0/manual/api/reference/host/object
+	TLSAccept int
+
+	// MonitoredBy Source monitoring the host, Zabbix 7.0+
+	// see "monitored_by" in: https://www.zabbix.com/documentation/7.0/manual/api/reference/host/object
+	MonitoredBy int
 )
 
 const (
@@ -29,6 +49,52 @@ const (
 	InventoryAutomatic InventoryMode = 1
 )
 
+// MarshalJSON encodes the mode as a string like Zabbix returns it
+func (m InventoryMode) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strconv.Itoa(int(m)))), nil
+}
+
+// UnmarshalJSON decodes the mode sent as a string or a number, an empty string being left out
+func (m *InventoryMode) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("Invalid inventory mode %s", b)
+	}
+	*m = InventoryMode(n)
+	return nil
+}
+
+const (
+	// TLSConnectUnencrypted no encryption (default)
+	TLSConnectUnencrypted TLSConnect = 1
+	// TLSConnectPSK pre-shared key
+	TLSConnectPSK TLSConnect = 2
+	// TLSConnectCertificate certificate
+	TLSConnectCertificate TLSConnect = 4
+)
+
+const (
+	// TLSAcceptUnencrypted no encryption (default)
+	TLSAcceptUnencrypted TLSAccept = 1
+	// TLSAcceptPSK pre-shared key
+	TLSAcceptPSK TLSAccept = 2
+	// TLSAcceptCertificate certificate
+	TLSAcceptCertificate TLSAccept = 4
+)
+
+const (
+	// MonitoredByServer Zabbix server (default)
+	MonitoredByServer MonitoredBy = 0
+	// MonitoredByProxy proxy, ProxyID
+	MonitoredByProxy MonitoredBy = 1
+	// MonitoredByProxyGroup proxy group, ProxyGroupID
+	MonitoredByProxyGroup MonitoredBy = 2
+)
+
 const (
 	// Monitored monitored host(default)
 	Monitored StatusType = 0
@@ -39,17 +105,24 @@ const (
 // Host represent Zabbix host object
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/host/object
 type Host struct {
-	HostID     string        `json:"hostid,omitempty"`
-	Host       string        `json:"host"`
-	Available  AvailableType `json:"available,string"`
-	Error      string        `json:"error"`
-	Name       string        `json:"name"`
-	Status     StatusType    `json:"status,string"`
-	UserMacros Macros        `json:"macros,omitempty"`
-
-	RawInventory  json.RawMessage `json:"inventory,omitempty"`
-	Inventory     Inventory       `json:"-"`
-	InventoryMode InventoryMode   `json:"inventory_mode,string"`
+	HostID      string        `json:"hostid,omitempty"`
+	Host        string        `json:"host"`
+	Available   AvailableType `json:"available,string"`
+	Error       string        `json:"error"`
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Status      StatusType    `json:"status,string"`
+	UserMacros  Macros        `json:"macros,omitempty"`
+	Tags        Tags          `json:"tags,omitempty"`
+
+	RawInventory json.RawMessage `json:"inventory,omitempty"`
+	Inventory    Inventory       `json:"-"`
+	// InventoryMode is read from inventory_mode or, when selected, from the inventory,
+	// InventoryDisabled when there is none
+	InventoryMode InventoryMode `json:"inventory_mode"`
+
+	// Flags is read only, FlagDiscovered for hosts created by host prototypes
+	Flags FlagsType `json:"flags,omitempty,string"`
 
 	// Fields below used only when creating hosts
 	GroupIds         HostGroupIDs   `json:"groups,omitempty"`
@@ -59,11 +132,50 @@ type Host struct {
 	// templates are read back from this one
 	ParentTemplateIDs TemplateIDs `json:"parentTemplates,omitempty"`
 	ProxyID           string      `json:"proxy_hostid,omitempty"`
+	// Zabbix 7.0+, MonitoredBy is set from ProxyID and ProxyGroupID when nil
+	ProxyGroupID string       `json:"proxy_groupid,omitempty"`
+	MonitoredBy  *MonitoredBy `json:"monitored_by,omitempty,string"`
+	// ProxyID is sent and read as proxyid since Zabbix 7.0
+	RawProxyID string `json:"proxyid,omitempty"`
+
+	// Encryption
+	TLSConnect TLSConnect `json:"tls_connect,omitempty,string"`
+	TLSAccept  TLSAccept  `json:"tls_accept,omitempty,string"`
+	TLSIssuer  string     `json:"tls_issuer,omitempty"`
+	TLSSubject string     `json:"tls_subject,omitempty"`
+	// PSK fields are write only since Zabbix 5.4 and left empty when read
+	TLSPSKIdentity string `json:"tls_psk_identity,omitempty"`
+	TLSPSK         string `json:"tls_psk,omitempty"`
+}
+
+// IsDiscovered reports whether the host was created by a host prototype.
+// Such hosts are deleted by their discovery rule, not by host.delete.
+func (h Host) IsDiscovered() bool {
+	return h.Flags == FlagDiscovered
 }
 
 // Hosts is an array of Host
 type Hosts []Host
 
+// WithoutDiscovered returns the hosts which were not created by discovery
+func (hosts Hosts) WithoutDiscovered() (res Hosts) {
+	for _, h := range hosts {
+		if !h.IsDiscovered() {
+			res = append(res, h)
+		}
+	}
+	return
+}
+
+// DiscoveredHostsError is returned when deleting hosts created by host prototypes
+type DiscoveredHostsError struct {
+	Hosts []string
+}
+
+func (e *DiscoveredHostsError) Error() string {
+	return fmt.Sprintf("Cannot delete discovered hosts %s, they are removed by their discovery rule; skip them with Hosts.WithoutDiscovered.", strings.Join(e.Hosts, ", "))
+}
+
 // HostsGet Wrapper for host.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/host/get
 func (api *API) HostsGet(params Params) (res Hosts, err error) {
@@ -97,14 +209,22 @@ func (api *API) HostsGet(params Params) (res Hosts, err error) {
 			res[i].Interfaces[j].Details = &out
 		}
 
+		if h.RawProxyID != "" {
+			res[i].ProxyID, res[i].RawProxyID = h.RawProxyID, ""
+		}
+
 		// fix up host inventory if present
 		if len(h.RawInventory) == 0 {
 			continue
 		}
 
-		// if its an empty array
+		// if its an empty array, the inventory is disabled
 		asStr := string(h.RawInventory)
-		if asStr == "[]" || asStr == "{}" {
+		if asStr == "[]" {
+			res[i].InventoryMode = InventoryDisabled
+			continue
+		}
+		if asStr == "{}" {
 			continue
 		}
 
@@ -114,12 +234,53 @@ func (api *API) HostsGet(params Params) (res Hosts, err error) {
 			api.printf("got error during unmarshal %s", err)
 			panic(err)
 		}
+		// the mode is part of the inventory before Zabbix 4.4
+		if mode, ok := inv["inventory_mode"]; ok {
+			if n, err := strconv.Atoi(mode); err == nil {
+				res[i].InventoryMode = InventoryMode(n)
+			}
+			delete(inv, "inventory_mode")
+		}
 		res[i].Inventory = inv
 	}
 
 	return
 }
 
+// HostGetOptions typed parameters for host.get
+type HostGetOptions struct {
+	GetOptions
+	HostIDs     []string
+	GroupIDs    []string
+	TemplateIDs []string
+	// SelectInterfaces fills Interfaces, along with their Details
+	SelectInterfaces bool
+	// SelectTags fills Tags, those set by discovery have Automatic "1"
+	SelectTags bool
+}
+
+func (o HostGetOptions) params() Params {
+	params := o.GetOptions.params()
+	params.setIDs("hostids", o.HostIDs)
+	params.setIDs("groupids", o.GroupIDs)
+	params.setIDs("templateids", o.TemplateIDs)
+	if o.SelectInterfaces {
+		params["selectInterfaces"] = "extend"
+	}
+	if o.SelectTags {
+		params["selectTags"] = "extend"
+	}
+	return params
+}
+
+// HostsGetWithOptions Wrapper for host.get using typed options
+func (api *API) HostsGetWithOptions(opts HostGetOptions) (res Hosts, err error) {
+	if res, err = api.HostsGet(opts.params()); err == nil {
+		err = opts.truncated(len(res))
+	}
+	return
+}
+
 // HostsGetByHostGroupIds Gets hosts by host group Ids.
 func (api *API) HostsGetByHostGroupIds(ids []string) (res Hosts, err error) {
 	return api.HostsGet(Params{"groupids": ids})
@@ -166,32 +327,127 @@ func (api *API) HostGetByHost(host string) (res *Host, err error) {
 	return
 }
 
-// handle manual marshal
-func prepHosts(hosts Hosts) {
-	for i := 0; i < len(hosts); i++ {
-		h := hosts[i]
-		for j := 0; j < len(h.Interfaces); j++ {
-			in := h.Interfaces[j]
+// ResolveHostIDs Gets the id of hosts by technical name in one call.
+// Resolved ids are returned along with a NotFoundError if some names are unknown.
+func (api *API) ResolveHostIDs(names []string) (res map[string]string, err error) {
+	hosts, err := api.HostsGet(Params{
+		"output": []string{"hostid", "host"},
+		"filter": map[string]interface{}{"host": names},
+	})
+	if err != nil {
+		return
+	}
 
-			if in.Details == nil {
-				continue
+	res = make(map[string]string, len(hosts))
+	for _, h := range hosts {
+		res[h.Host] = h.HostID
+	}
+	err = checkResolved("hosts", names, res)
+	return
+}
+
+var pskRegexp = regexp.MustCompile(`^([0-9a-fA-F]{2}){16,256}$`)
+
+// usesPSK reports whether the host connects or accepts connections with a pre-shared key
+func (h Host) usesPSK() bool {
+	return h.TLSConnect == TLSConnectPSK || h.TLSAccept&TLSAcceptPSK != 0
+}
+
+// validateHostsPSK checks the pre-shared key of the hosts,
+// when required is set hosts using PSK must have one.
+func validateHostsPSK(hosts Hosts, required bool) error {
+	for _, h := range hosts {
+		if h.TLSPSK == "" && h.TLSPSKIdentity == "" && (!required || !h.usesPSK()) {
+			continue
+		}
+		if h.TLSPSKIdentity == "" || len(h.TLSPSKIdentity) > 128 {
+			return fmt.Errorf("Host %s: PSK identity must be 1 to 128 characters", h.Host)
+		}
+		if !pskRegexp.MatchString(h.TLSPSK) {
+			return fmt.Errorf("Host %s: PSK must be 32 to 512 hexadecimal digits", h.Host)
+		}
+	}
+	return nil
+}
+
+// prepHostsProxy checks the proxy fields and moves them to the 7.0 ones when needed
+func (api *API) prepHostsProxy(hosts Hosts) error {
+	v := 0
+	for _, h := range hosts {
+		if h.ProxyID == "" && h.ProxyGroupID == "" && h.MonitoredBy == nil {
+			continue
+		}
+		// host.get returns "0" for the one not in use
+		if h.ProxyGroupID != "" && h.ProxyGroupID != "0" && h.ProxyID != "" && h.ProxyID != "0" {
+			return fmt.Errorf("Host %s: proxy and proxy group are mutually exclusive", h.Host)
+		}
+		if v == 0 {
+			var err error
+			if v, err = api.ServerVersion(); err != nil {
+				return err
 			}
+		}
+		if v < 70000 && ((h.ProxyGroupID != "" && h.ProxyGroupID != "0") || h.MonitoredBy != nil) {
+			return fmt.Errorf("Host %s: proxy groups need Zabbix 7.0", h.Host)
+		}
+	}
+	if v < 70000 {
+		return nil
+	}
 
-			asB, _ := json.Marshal(in.Details)
-			hosts[i].Interfaces[j].RawDetails = json.RawMessage(asB)
+	for i, h := range hosts {
+		if h.ProxyID != "" {
+			hosts[i].RawProxyID, hosts[i].ProxyID = h.ProxyID, ""
+		}
+		if h.MonitoredBy != nil || (h.ProxyID == "" && h.ProxyGroupID == "") {
+			continue
 		}
+		by := MonitoredByServer
+		if h.ProxyGroupID != "" && h.ProxyGroupID != "0" {
+			by = MonitoredByProxyGroup
+		} else if h.ProxyID != "" && h.ProxyID != "0" {
+			by = MonitoredByProxy
+		}
+		hosts[i].MonitoredBy = &by
+	}
+	return nil
+}
+
+// prepHosts returns copies of the hosts to send, handling manual marshal
+// and clearing read only fields without changing the hosts given
+func prepHosts(hosts Hosts) Hosts {
+	payload := make(Hosts, len(hosts))
+	for i, h := range hosts {
+		// read only
+		h.Flags = FlagPlain
+		h.Interfaces = append(HostInterfaces(nil), h.Interfaces...)
+		for j := range h.Interfaces {
+			h.Interfaces[j].HostID = ""
+		}
+		prepInterfaces(h.Interfaces)
+
 		if h.Inventory != nil {
 			asB, _ := json.Marshal(h.Inventory)
-			hosts[i].RawInventory = json.RawMessage(asB)
+			h.RawInventory = json.RawMessage(asB)
 		}
+		payload[i] = h
 	}
+	return payload
 }
 
 // HostsCreate Wrapper for host.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/host/create
 func (api *API) HostsCreate(hosts Hosts) (err error) {
-	prepHosts(hosts)
-	response, err := api.CallWithError("host.create", hosts)
+	if err = validateHostsPSK(hosts, true); err != nil {
+		return
+	}
+	if err = api.prepHostsProxy(hosts); err != nil {
+		return
+	}
+	for i := range hosts {
+		api.prepMacroTypes(hosts[i].UserMacros)
+	}
+	response, err := api.CallWithError("host.create", prepHosts(hosts))
 	if err != nil {
 		return
 	}
@@ -207,15 +463,63 @@ func (api *API) HostsCreate(hosts Hosts) (err error) {
 // HostsUpdate Wrapper for host.update
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/host/update
 func (api *API) HostsUpdate(hosts Hosts) (err error) {
-	prepHosts(hosts)
-	_, err = api.CallWithError("host.update", hosts)
+	if err = validateHostsPSK(hosts, false); err != nil {
+		return
+	}
+	if err = api.prepHostsProxy(hosts); err != nil {
+		return
+	}
+	for i := range hosts {
+		api.prepMacroTypes(hosts[i].UserMacros)
+	}
+	_, err = api.CallWithError("host.update", prepHosts(hosts))
 	return
 }
 
+// UpdateHostFields Wrapper for host.update sending only the given fields and the host id.
+func (api *API) UpdateHostFields(hostID string, fields map[string]interface{}) (err error) {
+	return api.updateFields("host.update", "hostid", hostID, fields)
+}
+
+// HostSetTags Replaces the manual tags of the host by tags, keeping the tags set by discovery.
+// host.update replaces all tags, automatic ones are sent back as read.
+func (api *API) HostSetTags(hostID string, tags Tags) (err error) {
+	hosts, err := api.HostsGet(Params{"output": []string{"hostid"}, "hostids": hostID, "selectTags": "extend"})
+	if err != nil {
+		return
+	}
+	if len(hosts) != 1 {
+		e := ExpectedOneResult(len(hosts))
+		return &e
+	}
+
+	merged := tags.local()
+	if merged == nil {
+		merged = Tags{}
+	}
+	for _, t := range hosts[0].Tags {
+		if t.Automatic == "1" {
+			merged = append(merged, t)
+		}
+	}
+	return api.updateTags("host.update", "hostid", []string{hostID}, []Tags{merged})
+}
+
 // HostsDelete Wrapper for host.delete
 // Cleans HostId in all hosts elements if call succeed.
+// Hosts known to be discovered are refused with a DiscoveredHostsError before any call.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/host/delete
 func (api *API) HostsDelete(hosts Hosts) (err error) {
+	discovered := []string{}
+	for _, host := range hosts {
+		if host.IsDiscovered() {
+			discovered = append(discovered, host.Host)
+		}
+	}
+	if len(discovered) > 0 {
+		return &DiscoveredHostsError{discovered}
+	}
+
 	ids := make([]string, len(hosts))
 	for i, host := range hosts {
 		ids[i] = host.HostID
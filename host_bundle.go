@@ -0,0 +1,63 @@
+package zabbix
+
+import "sync"
+
+// HostBundle is the full configuration of a host
+type HostBundle struct {
+	// Host with its interfaces, macros, tags and linked templates
+	Host     Host
+	Items    Items
+	Triggers Triggers
+	Graphs   Graphs
+}
+
+// HostFullConfig Gets the host with its items, triggers and graphs, queried concurrently.
+// Calls are bound to Config.BaseContext like any other.
+func (api *API) HostFullConfig(hostID string) (res *HostBundle, err error) {
+	if err = api.ctxErr(); err != nil {
+		return
+	}
+
+	var (
+		wg     sync.WaitGroup
+		hosts  Hosts
+		bundle HostBundle
+		errs   [4]error
+	)
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		hosts, errs[0] = api.HostsGet(Params{
+			"hostids":               hostID,
+			"selectInterfaces":      "extend",
+			"selectMacros":          "extend",
+			"selectTags":            "extend",
+			"selectParentTemplates": []string{"templateid"},
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		bundle.Items, errs[1] = api.ItemsGet(Params{"hostids": hostID, "selectTags": "extend", "selectPreprocessing": "extend"})
+	}()
+	go func() {
+		defer wg.Done()
+		bundle.Triggers, errs[2] = api.TriggersGet(Params{"hostids": hostID, "selectTags": "extend", "selectDependencies": []string{"triggerid"}})
+	}()
+	go func() {
+		defer wg.Done()
+		bundle.Graphs, errs[3] = api.GraphsGetByHostIDs([]string{hostID})
+	}()
+	wg.Wait()
+
+	for _, e := range errs {
+		if e != nil {
+			return nil, e
+		}
+	}
+	if len(hosts) != 1 {
+		e := ExpectedOneResult(len(hosts))
+		return nil, &e
+	}
+	bundle.Host = hosts[0]
+	return &bundle, nil
+}
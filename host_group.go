@@ -1,9 +1,15 @@
 package zabbix
 
+import "fmt"
+
 type (
 	// InternalType (readonly) Whether the group is used internally by the system. An internal group cannot be deleted.
 	// see "internal" in https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/object
 	InternalType int
+
+	// FlagsType (readonly) Origin of the object
+	// see "flags" in https://www.zabbix.com/documentation/6.0/manual/api/reference/hostgroup/object
+	FlagsType int
 )
 
 const (
@@ -13,17 +19,40 @@ const (
 	Internal InternalType = 1
 )
 
+const (
+	// FlagPlain (default) plain object
+	FlagPlain FlagsType = 0
+	// FlagDiscovered object created by low level discovery
+	FlagDiscovered FlagsType = 4
+)
+
 // HostGroup represent Zabbix host group object
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/object
 type HostGroup struct {
 	GroupID  string       `json:"groupid,omitempty"`
 	Name     string       `json:"name"`
 	Internal InternalType `json:"internal,omitempty,string"`
+	Flags    FlagsType    `json:"flags,omitempty,string"`
+}
+
+// IsDiscovered reports whether the group was created by host prototype discovery
+func (g HostGroup) IsDiscovered() bool {
+	return g.Flags == FlagDiscovered
 }
 
 // HostGroups is an array of HostGroup
 type HostGroups []HostGroup
 
+// WithoutDiscovered returns the groups which were not created by discovery
+func (hostGroups HostGroups) WithoutDiscovered() (res HostGroups) {
+	for _, g := range hostGroups {
+		if !g.IsDiscovered() {
+			res = append(res, g)
+		}
+	}
+	return
+}
+
 // HostGroupID represent Zabbix GroupID
 type HostGroupID struct {
 	GroupID string `json:"groupid"`
@@ -44,20 +73,115 @@ func (api *API) HostGroupsGet(params Params) (res HostGroups, err error) {
 
 // HostGroupGetByID Gets host group by Id only if there is exactly 1 matching host group.
 func (api *API) HostGroupGetByID(id string) (res *HostGroup, err error) {
-	groups, err := api.HostGroupsGet(Params{"groupids": id})
+	res = &HostGroup{}
+	if err = api.GetOne("hostgroup.get", Params{"groupids": id}, res); err != nil {
+		res = nil
+	}
+	return
+}
+
+// ResolveHostGroupIDs Gets the id of host groups by name in one call.
+// Resolved ids are returned along with a NotFoundError if some names are unknown.
+func (api *API) ResolveHostGroupIDs(names []string) (res map[string]string, err error) {
+	groups, err := api.HostGroupsGet(Params{
+		"output": []string{"groupid", "name"},
+		"filter": map[string]interface{}{"name": names},
+	})
+	if err != nil {
+		return
+	}
+
+	res = make(map[string]string, len(groups))
+	for _, g := range groups {
+		res[g.Name] = g.GroupID
+	}
+	err = checkResolved("host groups", names, res)
+	return
+}
+
+// HostGroupDisableHosts Sets all hosts of the group unmonitored, returns the number of hosts updated.
+func (api *API) HostGroupDisableHosts(groupID string) (int, error) {
+	return api.hostGroupSetStatus(groupID, Unmonitored)
+}
+
+// HostGroupEnableHosts Sets all hosts of the group monitored, returns the number of hosts updated.
+func (api *API) HostGroupEnableHosts(groupID string) (int, error) {
+	return api.hostGroupSetStatus(groupID, Monitored)
+}
+
+func (api *API) hostGroupSetStatus(groupID string, status StatusType) (n int, err error) {
+	hosts, err := api.HostsGet(Params{"groupids": groupID, "output": []string{"hostid"}})
 	if err != nil {
 		return
 	}
 
-	if len(groups) == 1 {
-		res = &groups[0]
-	} else {
-		e := ExpectedOneResult(len(groups))
-		err = &e
+	hostIDs := make([]string, len(hosts))
+	for i, h := range hosts {
+		hostIDs[i] = h.HostID
 	}
+	err = api.chunked(hostIDs, func(chunk []string) error {
+		ids := make([]map[string]string, 0, len(chunk))
+		for _, id := range chunk {
+			ids = append(ids, map[string]string{"hostid": id})
+		}
+		if _, err := api.CallWithError("host.massupdate", Params{"hosts": ids, "status": status}); err != nil {
+			return err
+		}
+		n += len(ids)
+		return nil
+	})
 	return
 }
 
+// HostGroupDeleteSafe Deletes the host group after moving the hosts it is the only group of
+// to the fallback group, hosts having other groups simply leave it.
+func (api *API) HostGroupDeleteSafe(groupID, fallbackGroupID string) (err error) {
+	if groupID == fallbackGroupID {
+		return fmt.Errorf("Fallback group %s is the group to delete", groupID)
+	}
+
+	// host groups are selected with selectHostGroups since 6.2
+	selectGroups := "selectGroups"
+	if v, err := api.ServerVersion(); err != nil {
+		return err
+	} else if v >= 60200 {
+		selectGroups = "selectHostGroups"
+	}
+	var hosts []struct {
+		HostID     string       `json:"hostid"`
+		Groups     HostGroupIDs `json:"groups"`
+		HostGroups HostGroupIDs `json:"hostgroups"`
+	}
+	err = api.CallWithErrorParse("host.get", Params{
+		"output":     []string{"hostid"},
+		"groupids":   groupID,
+		selectGroups: []string{"groupid"},
+	}, &hosts)
+	if err != nil {
+		return
+	}
+
+	lone := []string{}
+	for _, h := range hosts {
+		if len(h.Groups)+len(h.HostGroups) == 1 {
+			lone = append(lone, h.HostID)
+		}
+	}
+	err = api.chunked(lone, func(chunk []string) error {
+		ids := make([]map[string]string, 0, len(chunk))
+		for _, id := range chunk {
+			ids = append(ids, map[string]string{"hostid": id})
+		}
+		_, err := api.CallWithError("host.massupdate", Params{"hosts": ids, "groups": HostGroupIDs{{fallbackGroupID}}})
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	return api.HostGroupsDeleteByIds([]string{groupID})
+}
+
 // HostGroupsCreate Wrapper for hostgroup.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/create
 func (api *API) HostGroupsCreate(hostGroups HostGroups) (err error) {
@@ -81,10 +205,41 @@ func (api *API) HostGroupsUpdate(hostGroups HostGroups) (err error) {
 	return
 }
 
+// HostGroupMassAdd Wrapper for hostgroup.massadd adding the hosts to all the groups.
+// https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/massadd
+func (api *API) HostGroupMassAdd(groupIDs, hostIDs []string) (err error) {
+	if len(groupIDs) == 0 || len(hostIDs) == 0 {
+		return fmt.Errorf("Host group massadd needs groups and hosts, got %d groups and %d hosts", len(groupIDs), len(hostIDs))
+	}
+	groups := make(HostGroupIDs, len(groupIDs))
+	for i, id := range groupIDs {
+		groups[i].GroupID = id
+	}
+	return api.chunked(hostIDs, func(chunk []string) error {
+		hosts := make([]map[string]string, 0, len(chunk))
+		for _, id := range chunk {
+			hosts = append(hosts, map[string]string{"hostid": id})
+		}
+		_, err := api.CallWithError("hostgroup.massadd", Params{"groups": groups, "hosts": hosts})
+		return err
+	})
+}
+
 // HostGroupsDelete Wrapper for hostgroup.delete
 // Cleans GroupId in all hostGroups elements if call succeed.
+// Fails with DiscoveredError if any group is known to be discovered, see WithoutDiscovered.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/delete
 func (api *API) HostGroupsDelete(hostGroups HostGroups) (err error) {
+	discovered := []string{}
+	for _, group := range hostGroups {
+		if group.IsDiscovered() {
+			discovered = append(discovered, group.Name)
+		}
+	}
+	if len(discovered) > 0 {
+		return &DiscoveredError{"host groups", discovered}
+	}
+
 	ids := make([]string, len(hostGroups))
 	for i, group := range hostGroups {
 		ids[i] = group.GroupID
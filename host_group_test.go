@@ -1,6 +1,7 @@
 package zabbix_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"reflect"
@@ -64,3 +65,146 @@ func TestHostGroups(t *testing.T) {
 		t.Errorf("Error deleting group.\nOld groups: %#v\nNew groups: %#v", groups, groups2)
 	}
 }
+
+func TestHostGroupsDeleteDiscovered(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return []map[string]string{{"groupid": "1", "name": "Linux", "flags": "0"}, {"groupid": "2", "name": "web01 services", "flags": "4"}}, nil
+	})
+
+	groups, err := api.HostGroupsGet(zapi.Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if groups[0].IsDiscovered() || !groups[1].IsDiscovered() {
+		t.Errorf("Bad groups: %#v", groups)
+	}
+	if _, ok := api.HostGroupsDelete(groups).(*zapi.DiscoveredError); !ok {
+		t.Error("Expected discovered group deletion to be refused")
+	}
+	if plain := groups.WithoutDiscovered(); len(plain) != 1 || plain[0].GroupID != "1" {
+		t.Errorf("Bad plain groups: %#v", plain)
+	}
+}
+
+func TestResolveHostGroupIDs(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return []map[string]string{{"groupid": "2", "name": "Linux servers"}}, nil
+	})
+
+	ids, err := api.ResolveHostGroupIDs([]string{"Linux servers", "Missing"})
+	e, ok := err.(*zapi.NotFoundError)
+	if !ok || !reflect.DeepEqual(e.Names, []string{"Missing"}) {
+		t.Errorf("Expected missing group error, got %#v", err)
+	}
+	if ids["Linux servers"] != "2" {
+		t.Errorf("Bad ids: %#v", ids)
+	}
+}
+
+func TestHostGroupDisableHosts(t *testing.T) {
+	var sent struct {
+		Hosts  []map[string]string `json:"hosts"`
+		Status int                 `json:"status"`
+	}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method == "host.get" {
+			return []map[string]string{{"hostid": "1"}, {"hostid": "2"}}, nil
+		}
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"hostids": {"1", "2"}}, nil
+	})
+
+	n, err := api.HostGroupDisableHosts("5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || len(sent.Hosts) != 2 || sent.Status != int(zapi.Unmonitored) {
+		t.Errorf("Bad update of %d hosts: %#v", n, sent)
+	}
+}
+
+func TestHostGroupDeleteSafe(t *testing.T) {
+	var moved map[string]interface{}
+	methods := []string{}
+	api := getMockAPI(t, zapi.Config{Version: 60000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		methods = append(methods, method)
+		switch method {
+		case "host.get":
+			return []interface{}{
+				map[string]interface{}{"hostid": "1", "groups": []map[string]string{{"groupid": "42"}}},
+				map[string]interface{}{"hostid": "2", "groups": []map[string]string{{"groupid": "42"}, {"groupid": "5"}}},
+			}, nil
+		case "host.massupdate":
+			json.Unmarshal(params, &moved)
+			return map[string][]string{"hostids": {"1"}}, nil
+		}
+		return map[string][]string{"groupids": {"42"}}, nil
+	})
+
+	if err := api.HostGroupDeleteSafe("42", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if len(methods) != 3 || methods[2] != "hostgroup.delete" {
+		t.Errorf("Bad calls: %v", methods)
+	}
+	expected := map[string]interface{}{
+		"hosts":  []interface{}{map[string]interface{}{"hostid": "1"}},
+		"groups": []interface{}{map[string]interface{}{"groupid": "2"}},
+	}
+	if !reflect.DeepEqual(moved, expected) {
+		t.Errorf("Bad hosts moved: %#v", moved)
+	}
+}
+
+func TestHostGroupMassAdd(t *testing.T) {
+	members := map[string][]string{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "hostgroup.create":
+			return map[string][]string{"groupids": {"42"}}, nil
+		case "hostgroup.massadd":
+			var p struct {
+				Groups zapi.HostGroupIDs   `json:"groups"`
+				Hosts  []map[string]string `json:"hosts"`
+			}
+			json.Unmarshal(params, &p)
+			for _, g := range p.Groups {
+				for _, h := range p.Hosts {
+					members[g.GroupID] = append(members[g.GroupID], h["hostid"])
+				}
+			}
+			return map[string][]string{"groupids": {"42"}}, nil
+		case "host.get":
+			var p struct {
+				GroupIDs string `json:"groupids"`
+			}
+			json.Unmarshal(params, &p)
+			hosts := []map[string]string{}
+			for _, id := range members[p.GroupIDs] {
+				hosts = append(hosts, map[string]string{"hostid": id})
+			}
+			return hosts, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	groups := zapi.HostGroups{{Name: "Databases"}}
+	if err := api.HostGroupsCreate(groups); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.HostGroupMassAdd([]string{groups[0].GroupID}, []string{"10084"}); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := api.HostsGet(zapi.Params{"groupids": groups[0].GroupID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].HostID != "10084" {
+		t.Errorf("Host not added to the group: %#v", hosts)
+	}
+
+	if err := api.HostGroupMassAdd([]string{"42"}, nil); err == nil {
+		t.Error("Expected error without hosts")
+	}
+}
@@ -1,6 +1,9 @@
 package zabbix
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 type (
 	// InterfaceType different interface type
@@ -25,6 +28,7 @@ const (
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/hostinterface/object
 type HostInterface struct {
 	InterfaceID string               `json:"interfaceid,omitempty"`
+	HostID      string               `json:"hostid,omitempty"`
 	DNS         string               `json:"dns"`
 	IP          string               `json:"ip"`
 	Main        string               `json:"main"`
@@ -52,3 +56,109 @@ type HostInterfaceDetail struct {
 }
 
 type HostInterfaceDetails []HostInterfaceDetail
+
+// prepInterfaces sets the details to send
+func prepInterfaces(interfaces HostInterfaces) {
+	for i, in := range interfaces {
+		if in.Details == nil {
+			continue
+		}
+		asB, _ := json.Marshal(in.Details)
+		interfaces[i].RawDetails = json.RawMessage(asB)
+	}
+}
+
+// HostInterfacesGet Wrapper for hostinterface.get
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/hostinterface/get
+func (api *API) HostInterfacesGet(params Params) (res HostInterfaces, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	if err = api.CallWithErrorParse("hostinterface.get", params, &res); err != nil {
+		return
+	}
+	for i, in := range res {
+		// interfaces without details have an empty array
+		if len(in.RawDetails) == 0 || string(in.RawDetails) == "[]" {
+			continue
+		}
+		details := &HostInterfaceDetail{}
+		if err = json.Unmarshal(in.RawDetails, details); err != nil {
+			return nil, err
+		}
+		res[i].Details = details
+	}
+	return
+}
+
+// HostInterfacesCreate Wrapper for hostinterface.create
+// SNMP interfaces need their Details, the SNMP version and credentials.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/hostinterface/create
+func (api *API) HostInterfacesCreate(interfaces HostInterfaces) (err error) {
+	for _, in := range interfaces {
+		if in.HostID == "" {
+			return fmt.Errorf("Interface %s%s: HostID is required", in.IP, in.DNS)
+		}
+		if in.Type == SNMP && in.Details == nil && len(in.RawDetails) == 0 {
+			return fmt.Errorf("Interface %s%s: SNMP interfaces require Details", in.IP, in.DNS)
+		}
+	}
+	prepInterfaces(interfaces)
+	response, err := api.CallWithError("hostinterface.create", interfaces)
+	if err != nil {
+		return
+	}
+
+	interfaceids, err := resultIDs(response, "interfaceids")
+	if err != nil {
+		return
+	}
+	if len(interfaceids) != len(interfaces) {
+		return &ExpectedMore{len(interfaces), len(interfaceids)}
+	}
+	for i, id := range interfaceids {
+		interfaces[i].InterfaceID = id
+	}
+	return
+}
+
+// HostInterfacesUpdate Wrapper for hostinterface.update
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/hostinterface/update
+func (api *API) HostInterfacesUpdate(interfaces HostInterfaces) (err error) {
+	prepInterfaces(interfaces)
+	_, err = api.CallWithError("hostinterface.update", interfaces)
+	return
+}
+
+// HostInterfacesDeleteByIds Wrapper for hostinterface.delete
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/hostinterface/delete
+func (api *API) HostInterfacesDeleteByIds(ids []string) (err error) {
+	response, err := api.CallWithError("hostinterface.delete", ids)
+	if err != nil {
+		return
+	}
+
+	interfaceids, err := resultIDs(response, "interfaceids")
+	if err == nil && len(ids) != len(interfaceids) {
+		err = &ExpectedMore{len(ids), len(interfaceids)}
+	}
+	return
+}
+
+// HostInterfacesDelete Wrapper for hostinterface.delete
+// Cleans InterfaceID in all interfaces elements if call succeed.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/hostinterface/delete
+func (api *API) HostInterfacesDelete(interfaces HostInterfaces) (err error) {
+	ids := make([]string, len(interfaces))
+	for i, in := range interfaces {
+		ids[i] = in.InterfaceID
+	}
+
+	err = api.HostInterfacesDeleteByIds(ids)
+	if err == nil {
+		for i := range interfaces {
+			interfaces[i].InterfaceID = ""
+		}
+	}
+	return
+}
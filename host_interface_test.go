@@ -0,0 +1,60 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestHostInterfaces(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "hostinterface.get":
+			return []interface{}{
+				map[string]interface{}{"interfaceid": "1", "hostid": "10084", "type": "1", "ip": "127.0.0.1", "port": "10050", "details": []string{}},
+				map[string]interface{}{"interfaceid": "2", "hostid": "10084", "type": "2", "ip": "127.0.0.1", "port": "161",
+					"details": map[string]string{"version": "3", "securityname": "monitor", "securitylevel": "2"}},
+			}, nil
+		case "hostinterface.create":
+			json.Unmarshal(params, &sent)
+			return map[string][]string{"interfaceids": {"3"}}, nil
+		case "hostinterface.delete":
+			return map[string][]string{"interfaceids": {"3"}}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	interfaces, err := api.HostInterfacesGet(zapi.Params{"hostids": "10084"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interfaces) != 2 || interfaces[0].Details != nil || interfaces[1].Details == nil || interfaces[1].Details.SecurityName != "monitor" {
+		t.Fatalf("Bad interfaces: %#v", interfaces)
+	}
+
+	snmp := zapi.HostInterfaces{{HostID: "10084", Type: zapi.SNMP, IP: "10.0.0.5", Port: "161", UseIP: "1", Main: "0"}}
+	if err = api.HostInterfacesCreate(snmp); err == nil || sent != nil {
+		t.Error("Expected error for SNMP interface without details")
+	}
+	snmp[0].Details = &zapi.HostInterfaceDetail{
+		Version: "3", SecurityName: "monitor", SecurityLevel: "2",
+		AuthProtocol: "1", AuthPassphrase: "auth secret", PrivProtocol: "1", PrivPassphrase: "priv secret",
+	}
+	if err = api.HostInterfacesCreate(snmp); err != nil {
+		t.Fatal(err)
+	}
+	if snmp[0].InterfaceID != "3" {
+		t.Errorf("InterfaceID not backfilled: %#v", snmp[0])
+	}
+	details, _ := sent[0]["details"].(map[string]interface{})
+	if len(sent) != 1 || sent[0]["hostid"] != "10084" || details["version"] != "3" || details["privpassphrase"] != "priv secret" {
+		t.Errorf("Bad interface sent: %#v", sent)
+	}
+
+	if err = api.HostInterfacesDelete(snmp); err != nil || snmp[0].InterfaceID != "" {
+		t.Errorf("Bad delete: %v %#v", err, snmp)
+	}
+}
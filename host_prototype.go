@@ -0,0 +1,66 @@
+package zabbix
+
+// HostGroupPrototype is a group of discovered hosts, its name holding LLD macros
+type HostGroupPrototype struct {
+	Name string `json:"name"`
+}
+
+// HostGroupPrototypes is an array of HostGroupPrototype
+type HostGroupPrototypes []HostGroupPrototype
+
+// HostPrototype represent Zabbix host prototype object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/hostprototype/object
+type HostPrototype struct {
+	HostID string     `json:"hostid,omitempty"`
+	Host   string     `json:"host"`
+	Name   string     `json:"name,omitempty"`
+	Status StatusType `json:"status,string"`
+	// RuleID is the discovery rule of the prototype, only sent on create
+	RuleID string `json:"ruleid,omitempty"`
+	// InventoryMode is sent when set, Zabbix 4.4+
+	InventoryMode *InventoryMode `json:"inventory_mode,omitempty"`
+	// CustomInterfaces is "1" when Interfaces replace those of the host, Zabbix 5.2+
+	CustomInterfaces string `json:"custom_interfaces,omitempty"`
+	Discover         string `json:"discover,omitempty"`
+
+	GroupLinks      HostGroupIDs        `json:"groupLinks,omitempty"`
+	GroupPrototypes HostGroupPrototypes `json:"groupPrototypes,omitempty"`
+	TemplateIDs     TemplateIDs         `json:"templates,omitempty"`
+	UserMacros      Macros              `json:"macros,omitempty"`
+	Tags            Tags                `json:"tags,omitempty"`
+	Interfaces      HostInterfaces      `json:"interfaces,omitempty"`
+}
+
+// HostPrototypes is an array of HostPrototype
+type HostPrototypes []HostPrototype
+
+// HostPrototypesGet Wrapper for hostprototype.get
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/hostprototype/get
+func (api *API) HostPrototypesGet(params Params) (res HostPrototypes, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("hostprototype.get", params, &res)
+	return
+}
+
+// HostPrototypesCreate Wrapper for hostprototype.create
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/hostprototype/create
+func (api *API) HostPrototypesCreate(prototypes HostPrototypes) (err error) {
+	response, err := api.CallWithError("hostprototype.create", prototypes)
+	if err != nil {
+		return
+	}
+
+	hostids, err := resultIDs(response, "hostids")
+	if err != nil {
+		return
+	}
+	if len(hostids) != len(prototypes) {
+		return &ExpectedMore{len(prototypes), len(hostids)}
+	}
+	for i, id := range hostids {
+		prototypes[i].HostID = id
+	}
+	return
+}
@@ -0,0 +1,132 @@
+package zabbix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// HostSpec is everything needed to onboard a host, referring to groups and templates by name
+type HostSpec struct {
+	Host string
+	// Name is the visible name, Host when empty
+	Name string
+	// GroupNames are created when missing, at least one is required
+	GroupNames []string
+	// Interface is the main interface, Main and UseIP are set from IP and DNS when empty
+	Interface     HostInterface
+	TemplateNames []string
+	Macros        Macros
+	Tags          Tags
+	// Inventory enables the manual inventory mode when set
+	Inventory Inventory
+}
+
+func (s HostSpec) validate() error {
+	if s.Host == "" {
+		return errors.New("Host spec without host name")
+	}
+	if len(s.GroupNames) == 0 {
+		return fmt.Errorf("Host spec %s without group", s.Host)
+	}
+	if s.Interface.IP == "" && s.Interface.DNS == "" {
+		return fmt.Errorf("Host spec %s interface without IP nor DNS", s.Host)
+	}
+	for _, m := range s.Macros {
+		if !strings.HasPrefix(m.MacroName, "{$") || !strings.HasSuffix(m.MacroName, "}") {
+			return fmt.Errorf("Host spec %s has bad macro name %q", s.Host, m.MacroName)
+		}
+	}
+	return nil
+}
+
+// ensureHostGroupIDs resolves the host groups by name, creating the missing ones
+// and returning them as created
+func (api *API) ensureHostGroupIDs(names []string) (res map[string]string, created HostGroups, err error) {
+	res, err = api.ResolveHostGroupIDs(names)
+	notFound, ok := err.(*NotFoundError)
+	if !ok {
+		return
+	}
+
+	groups := make(HostGroups, len(notFound.Names))
+	for i, name := range notFound.Names {
+		groups[i].Name = name
+	}
+	if err = api.HostGroupsCreate(groups); err != nil {
+		return
+	}
+	for _, g := range groups {
+		res[g.Name] = g.GroupID
+	}
+	return res, groups, nil
+}
+
+// ProvisionHost Creates a monitored host from spec in one go: missing groups are created,
+// templates are resolved by name, and the host is created with its interface, templates,
+// macros, tags and inventory. Unknown templates fail with a NotFoundError before any change,
+// the groups created are deleted again when the host can not be created.
+func (api *API) ProvisionHost(spec HostSpec) (res *Host, err error) {
+	if err = spec.validate(); err != nil {
+		return
+	}
+
+	templates := TemplateIDs{}
+	if len(spec.TemplateNames) > 0 {
+		ids, err := api.ResolveTemplateIDs(spec.TemplateNames)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range spec.TemplateNames {
+			templates = append(templates, TemplateID{ids[name]})
+		}
+	}
+
+	groupIDs, created, err := api.ensureHostGroupIDs(spec.GroupNames)
+	if err != nil {
+		return
+	}
+	groups := make(HostGroupIDs, len(spec.GroupNames))
+	for i, name := range spec.GroupNames {
+		groups[i] = HostGroupID{groupIDs[name]}
+	}
+
+	iface := spec.Interface
+	if iface.Main == "" {
+		iface.Main = "1"
+	}
+	if iface.UseIP == "" {
+		iface.UseIP = "0"
+		if iface.IP != "" {
+			iface.UseIP = "1"
+		}
+	}
+
+	host := Host{
+		Host:        spec.Host,
+		Name:        spec.Name,
+		GroupIds:    groups,
+		Interfaces:  HostInterfaces{iface},
+		TemplateIDs: templates,
+		UserMacros:  spec.Macros,
+		Tags:        spec.Tags,
+	}
+	if host.Name == "" {
+		host.Name = spec.Host
+	}
+	if len(spec.Inventory) > 0 {
+		host.Inventory = spec.Inventory
+		host.InventoryMode = InventoryManual
+	}
+
+	hosts := Hosts{host}
+	if err = api.HostsCreate(hosts); err != nil {
+		if len(created) > 0 {
+			if e := api.HostGroupsDelete(created); e != nil {
+				api.printf("Could not delete the host groups created for %s: %s", spec.Host, e)
+			}
+		}
+		return
+	}
+	return &hosts[0], nil
+}
@@ -1,6 +1,7 @@
 package zabbix_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"reflect"
@@ -13,10 +14,11 @@ func CreateHost(group *zapi.HostGroup, t *testing.T) *zapi.Host {
 	name := fmt.Sprintf("%s-%d", getHost(), rand.Int())
 	iface := zapi.HostInterface{DNS: name, Port: "42", Type: zapi.Agent, UseIP: "0", Main: "1"}
 	hosts := zapi.Hosts{{
-		Host:       name,
-		Name:       "Name for " + name,
-		GroupIds:   zapi.HostGroupIDs{{group.GroupID}},
-		Interfaces: zapi.HostInterfaces{iface},
+		Host:        name,
+		Name:        "Name for " + name,
+		Description: "Description for " + name,
+		GroupIds:    zapi.HostGroupIDs{{group.GroupID}},
+		Interfaces:  zapi.HostInterfaces{iface},
 	}}
 
 	err := getAPI(t).HostsCreate(hosts)
@@ -96,3 +98,532 @@ func TestHosts(t *testing.T) {
 		t.Errorf("Bad hosts: %#v", hosts)
 	}
 }
+
+func TestUpdateHostFields(t *testing.T) {
+	var sent map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"hostids": {"42"}}, nil
+	})
+
+	err := api.UpdateHostFields("42", map[string]interface{}{"name": "renamed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"hostid": "42", "name": "renamed"}
+	if !reflect.DeepEqual(sent, expected) {
+		t.Errorf("Bad params: %#v", sent)
+	}
+}
+
+func TestHostsCreatePSK(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"hostids": {"42"}}, nil
+	})
+
+	hosts := zapi.Hosts{{
+		Host:       "psk-agent",
+		GroupIds:   zapi.HostGroupIDs{{GroupID: "2"}},
+		TLSConnect: zapi.TLSConnectPSK,
+		TLSAccept:  zapi.TLSAcceptPSK,
+	}}
+	if err := api.HostsCreate(hosts); err == nil {
+		t.Error("Expected error for PSK host without key")
+	}
+
+	hosts[0].TLSPSKIdentity = "psk-agent"
+	hosts[0].TLSPSK = "1f87b595725ac58dd977beef14b97461a7c1045b9a1c963065002c5473194952"
+	if err := api.HostsCreate(hosts); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0]["tls_connect"] != "2" || sent[0]["tls_psk"] != hosts[0].TLSPSK {
+		t.Errorf("Bad host sent: %#v", sent)
+	}
+}
+
+func TestHostsGetInterfaces(t *testing.T) {
+	var sent map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return []interface{}{map[string]interface{}{
+			"hostid": "10084",
+			"host":   "switch01",
+			"interfaces": []interface{}{map[string]interface{}{
+				"interfaceid": "5",
+				"type":        "2",
+				"ip":          "192.0.2.10",
+				"port":        "161",
+				"details":     map[string]string{"version": "2", "bulk": "1", "community": "{$SNMP_COMMUNITY}"},
+			}},
+		}}, nil
+	})
+
+	hosts, err := api.HostsGetWithOptions(zapi.HostGetOptions{HostIDs: []string{"10084"}, SelectInterfaces: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent["selectInterfaces"] != "extend" {
+		t.Errorf("Bad params: %#v", sent)
+	}
+	if len(hosts) != 1 || len(hosts[0].Interfaces) != 1 {
+		t.Fatalf("Bad hosts: %#v", hosts)
+	}
+	in := hosts[0].Interfaces[0]
+	if in.Type != zapi.SNMP || in.Details == nil || in.Details.Version != "2" || in.Details.Community != "{$SNMP_COMMUNITY}" {
+		t.Errorf("Bad interface: %#v", in)
+	}
+}
+
+func TestHostFullConfig(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{Serialize: true}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "host.get":
+			return []interface{}{map[string]interface{}{
+				"hostid": "10084",
+				"host":   "web01",
+				"macros": []map[string]string{{"macro": "{$PORT}", "value": "8080"}},
+				"tags":   []map[string]string{{"tag": "role", "value": "web"}},
+			}}, nil
+		case "item.get":
+			return []map[string]string{
+				{"itemid": "1", "hostid": "10084", "key_": "system.cpu.load"},
+				{"itemid": "2", "hostid": "10084", "key_": "net.tcp.service[http,,8080]"},
+			}, nil
+		case "trigger.get":
+			return []map[string]string{
+				{"triggerid": "11", "description": "High CPU load"},
+				{"triggerid": "12", "description": "Web service down"},
+			}, nil
+		}
+		return []interface{}{}, nil
+	})
+
+	bundle, err := api.HostFullConfig("10084")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Host.Host != "web01" || len(bundle.Host.UserMacros) != 1 || len(bundle.Host.Tags) != 1 {
+		t.Errorf("Bad host: %#v", bundle.Host)
+	}
+	if len(bundle.Items) != 2 || len(bundle.Triggers) != 2 || len(bundle.Graphs) != 0 {
+		t.Errorf("Bad bundle: %#v", bundle)
+	}
+}
+
+func TestHostsGetSortAndSearch(t *testing.T) {
+	var sent map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return []interface{}{}, nil
+	})
+
+	_, err := api.HostsGetWithOptions(zapi.HostGetOptions{GetOptions: zapi.GetOptions{
+		Search:      map[string]interface{}{"host": []string{"web", "db"}, "name": "prod"},
+		SearchByAny: true,
+		StartSearch: true,
+		SortField:   "status",
+		SortFields:  []string{"name"},
+		SortOrder:   zapi.SortDesc,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sent["sortfield"], []interface{}{"status", "name"}) || sent["sortorder"] != "DESC" {
+		t.Errorf("Bad sort params: %#v", sent)
+	}
+	if sent["searchByAny"] != true || sent["startSearch"] != true || sent["excludeSearch"] != nil {
+		t.Errorf("Bad search params: %#v", sent)
+	}
+}
+
+func TestProvisionHost(t *testing.T) {
+	api := getAPI(t)
+
+	group := CreateHostGroup(t)
+	defer DeleteHostGroup(group, t)
+	template := CreateTemplate(group, t)
+	defer DeleteTemplate(template, t)
+
+	name := fmt.Sprintf("%s-%d", getHost(), rand.Int())
+	newGroup := fmt.Sprintf("zabbix-testing-%d", rand.Int())
+	host, err := api.ProvisionHost(zapi.HostSpec{
+		Host:          name,
+		GroupNames:    []string{group.Name, newGroup},
+		Interface:     zapi.HostInterface{IP: "127.0.0.1", Port: "10050", Type: zapi.Agent},
+		TemplateNames: []string{template.Host},
+		Macros:        zapi.Macros{{MacroName: "{$PROVISIONED}", Value: "1"}},
+		Tags:          zapi.Tags{{Tag: "env", Value: "test"}},
+		Inventory:     zapi.Inventory{"location": "lab"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer DeleteHost(host, t)
+
+	groups, err := api.HostGroupsGet(zapi.Params{"filter": map[string]interface{}{"name": newGroup}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("Group %s not created", newGroup)
+	}
+	defer DeleteHostGroup(&groups[0], t)
+
+	hosts, err := api.HostsGet(zapi.Params{
+		"hostids":               host.HostID,
+		"selectParentTemplates": []string{"templateid"},
+		"selectMacros":          "extend",
+		"selectTags":            "extend",
+		"selectInventory":       []string{"location"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 {
+		t.Fatalf("Expected 1 host, got %d", len(hosts))
+	}
+	h := hosts[0]
+	if len(h.ParentTemplateIDs) != 1 || len(h.UserMacros) != 1 || !h.Tags.Has("env", "test") || h.Inventory["location"] != "lab" {
+		t.Errorf("Host not fully provisioned: %#v", h)
+	}
+}
+
+func TestProvisionHostMissingTemplate(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method != "template.get" {
+			t.Fatalf("Unexpected call %s before resolving templates", method)
+		}
+		return []map[string]string{}, nil
+	})
+
+	_, err := api.ProvisionHost(zapi.HostSpec{
+		Host:          "web01",
+		GroupNames:    []string{"Web servers"},
+		Interface:     zapi.HostInterface{IP: "10.0.0.1", Port: "10050", Type: zapi.Agent},
+		TemplateNames: []string{"Linux by Zabbix agent"},
+	})
+	if _, ok := err.(*zapi.NotFoundError); !ok {
+		t.Errorf("Expected NotFoundError, got %#v", err)
+	}
+}
+
+func TestProvisionHostCreateFailure(t *testing.T) {
+	var deleted []string
+	api := getMockAPI(t, zapi.Config{Version: 60000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "hostgroup.get":
+			return []map[string]string{{"groupid": "2", "name": "Linux servers"}}, nil
+		case "hostgroup.create":
+			return map[string][]string{"groupids": {"20"}}, nil
+		case "host.create":
+			return nil, &zapi.Error{Code: -32602, Message: "Invalid params.", Data: "Host with the same name \"web01\" already exists."}
+		case "hostgroup.delete":
+			json.Unmarshal(params, &deleted)
+			return map[string][]string{"groupids": deleted}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	_, err := api.ProvisionHost(zapi.HostSpec{
+		Host:       "web01",
+		GroupNames: []string{"Linux servers", "Web servers"},
+		Interface:  zapi.HostInterface{IP: "10.0.0.1", Port: "10050", Type: zapi.Agent},
+	})
+	if _, ok := err.(*zapi.Error); !ok {
+		t.Errorf("Expected host create error, got %#v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "20" {
+		t.Errorf("Created group not deleted: %v", deleted)
+	}
+}
+
+func TestHostsGetTruncated(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var p struct {
+			Limit int `json:"limit"`
+		}
+		json.Unmarshal(params, &p)
+		res := []map[string]string{}
+		for i := 0; i < 3 && i < p.Limit; i++ {
+			res = append(res, map[string]string{"hostid": fmt.Sprint(10084 + i)})
+		}
+		return res, nil
+	})
+
+	for _, test := range []struct {
+		limit     int
+		truncated bool
+	}{{2, true}, {3, true}, {4, false}} {
+		hosts, err := api.HostsGetWithOptions(zapi.HostGetOptions{GetOptions: zapi.GetOptions{Limit: test.limit, ErrorIfTruncated: true}})
+		if (err == zapi.ErrResultsTruncated) != test.truncated || (err != nil && err != zapi.ErrResultsTruncated) {
+			t.Errorf("Limit %d: unexpected error %v", test.limit, err)
+		}
+		if len(hosts) != test.limit && len(hosts) != 3 {
+			t.Errorf("Limit %d: results not returned: %#v", test.limit, hosts)
+		}
+	}
+
+	if _, err := api.HostsGetWithOptions(zapi.HostGetOptions{GetOptions: zapi.GetOptions{Limit: 2}}); err != nil {
+		t.Errorf("Truncation reported without ErrorIfTruncated: %v", err)
+	}
+}
+
+func TestHostsCreateProxyGroup(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{Version: 70000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "host.create":
+			json.Unmarshal(params, &sent)
+			return map[string][]string{"hostids": {"10600"}}, nil
+		case "host.get":
+			return []map[string]string{{"hostid": "10600", "host": "web01", "monitored_by": "2", "proxyid": "0", "proxy_groupid": "3"}}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	hosts := zapi.Hosts{{Host: "web01", GroupIds: zapi.HostGroupIDs{{"2"}}, ProxyGroupID: "3"}}
+	if err := api.HostsCreate(hosts); err != nil {
+		t.Fatal(err)
+	}
+	if sent[0]["monitored_by"] != "2" || sent[0]["proxy_groupid"] != "3" {
+		t.Errorf("Bad host sent: %#v", sent[0])
+	}
+	if _, ok := sent[0]["proxy_hostid"]; ok {
+		t.Errorf("proxy_hostid sent to 7.0: %#v", sent[0])
+	}
+
+	read, err := api.HostsGet(zapi.Params{"hostids": "10600"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 1 || read[0].MonitoredBy == nil || *read[0].MonitoredBy != zapi.MonitoredByProxyGroup ||
+		read[0].ProxyGroupID != "3" || read[0].ProxyID != "0" || read[0].RawProxyID != "" {
+		t.Errorf("Bad host read: %#v", read)
+	}
+
+	err = api.HostsCreate(zapi.Hosts{{Host: "web02", ProxyID: "1", ProxyGroupID: "3"}})
+	if err == nil {
+		t.Error("Expected error for proxy and proxy group")
+	}
+
+	// every host is checked, not only the first one with a proxy
+	sent = nil
+	err = api.HostsCreate(zapi.Hosts{{Host: "web03", ProxyID: "1"}, {Host: "web04", ProxyID: "1", ProxyGroupID: "3"}})
+	if err == nil || sent != nil {
+		t.Errorf("Expected error for proxy and proxy group on second host: %v %#v", err, sent)
+	}
+
+	sent = nil
+	hosts = zapi.Hosts{{Host: "web05"}, {Host: "web06", ProxyID: "1"}, {Host: "web07", ProxyGroupID: "3"}}
+	if err := api.HostsCreate(hosts); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 3 || sent[0]["monitored_by"] != nil || sent[1]["monitored_by"] != "1" || sent[1]["proxyid"] != "1" || sent[2]["monitored_by"] != "2" {
+		t.Errorf("Bad hosts sent: %#v", sent)
+	}
+}
+
+func TestHostsUpdateProxyRead(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{Version: 70000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "host.get":
+			return []map[string]string{{"hostid": "10600", "host": "web01", "monitored_by": "1", "proxyid": "5", "proxy_groupid": "0"}}, nil
+		case "host.update":
+			json.Unmarshal(params, &sent)
+			return map[string][]string{"hostids": {"10600"}}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	hosts, err := api.HostsGet(zapi.Params{"hostids": "10600"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = api.HostsUpdate(hosts); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0]["proxyid"] != "5" || sent[0]["monitored_by"] != "1" {
+		t.Errorf("Bad host sent: %#v", sent)
+	}
+}
+
+func TestHostsCreateProxyGroupPre70(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{Version: 60400}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	err := api.HostsCreate(zapi.Hosts{{Host: "web01", ProxyID: "1"}, {Host: "web02", ProxyGroupID: "3"}})
+	if err == nil {
+		t.Error("Expected error for proxy group on second host before 7.0")
+	}
+}
+
+func TestHostsDeleteDiscovered(t *testing.T) {
+	var deleted []map[string]string
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method == "host.delete" {
+			json.Unmarshal(params, &deleted)
+			return map[string][]string{"hostids": {"10084"}}, nil
+		}
+		return []map[string]string{
+			{"hostid": "10084", "host": "web01", "flags": "0"},
+			{"hostid": "10600", "host": "vm-042", "flags": "4"},
+		}, nil
+	})
+
+	hosts, err := api.HostsGet(zapi.Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hosts[0].IsDiscovered() || !hosts[1].IsDiscovered() {
+		t.Fatalf("Bad flags: %#v", hosts)
+	}
+
+	err = api.HostsDelete(hosts)
+	if e, ok := err.(*zapi.DiscoveredHostsError); !ok || len(e.Hosts) != 1 || e.Hosts[0] != "vm-042" || deleted != nil {
+		t.Errorf("Expected DiscoveredHostsError before any deletion, got %v", err)
+	}
+
+	if err = api.HostsDelete(hosts.WithoutDiscovered()); err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0]["hostid"] != "10084" {
+		t.Errorf("Bad hosts deleted: %v", deleted)
+	}
+}
+
+func TestHostsUpdateKeepsReadOnlyFields(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{Version: 60000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "host.get":
+			return []map[string]interface{}{{"hostid": "10600", "host": "vm-042", "flags": "4",
+				"interfaces": []map[string]string{{"interfaceid": "30", "hostid": "10600", "type": "1", "main": "1", "useip": "1", "ip": "10.0.0.42", "port": "10050"}}}}, nil
+		case "host.update":
+			json.Unmarshal(params, &sent)
+			return map[string][]string{"hostids": {"10600"}}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	hosts, err := api.HostsGet(zapi.Params{"hostids": "10600"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = api.HostsUpdate(hosts); err != nil {
+		t.Fatal(err)
+	}
+	if !hosts[0].IsDiscovered() || hosts[0].Interfaces[0].HostID != "10600" {
+		t.Errorf("Read only fields of the hosts changed: %#v", hosts[0])
+	}
+	interfaces, _ := sent[0]["interfaces"].([]interface{})
+	if _, ok := sent[0]["flags"]; ok || len(interfaces) != 1 {
+		t.Fatalf("Bad host sent: %#v", sent)
+	}
+	if _, ok := interfaces[0].(map[string]interface{})["hostid"]; ok {
+		t.Errorf("Interface host id sent: %#v", interfaces[0])
+	}
+}
+
+func TestHostsInventory(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var p map[string]interface{}
+		json.Unmarshal(params, &p)
+		if method != "host.get" || p["selectInventory"] != "extend" {
+			t.Errorf("Bad call %s: %s", method, params)
+		}
+		return []interface{}{
+			map[string]interface{}{"hostid": "1", "inventory": map[string]string{"os": "Linux", "serialno_a": "ABC123"}},
+			map[string]interface{}{"hostid": "2", "inventory": []string{}},
+			map[string]interface{}{"hostid": "3", "inventory": map[string]string{"os": "Windows"}},
+		}, nil
+	})
+
+	inventories, err := api.HostsInventory([]string{"1", "2", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inventories) != 2 || inventories["1"]["serialno_a"] != "ABC123" || inventories["3"]["os"] != "Windows" {
+		t.Errorf("Bad inventories: %#v", inventories)
+	}
+	if _, ok := inventories["2"]; ok {
+		t.Error("Expected host with inventory disabled left out")
+	}
+}
+
+func TestHostSetTags(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "host.get":
+			return []interface{}{map[string]interface{}{"hostid": "10084", "tags": []map[string]string{
+				{"tag": "env", "value": "prod", "automatic": "0"},
+				{"tag": "discovered", "value": "vm", "automatic": "1"},
+			}}}, nil
+		case "host.update":
+			json.Unmarshal(params, &sent)
+			return map[string][]string{"hostids": {"10084"}}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	if err := api.HostSetTags("10084", zapi.Tags{{Tag: "env", Value: "staging"}}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"tag": "env", "value": "staging"},
+		map[string]interface{}{"tag": "discovered", "value": "vm", "automatic": "1"},
+	}
+	if len(sent) != 1 || sent[0]["hostid"] != "10084" || !reflect.DeepEqual(sent[0]["tags"], expected) {
+		t.Errorf("Bad update: %#v", sent)
+	}
+}
+
+func TestHostsGetInventoryMode(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return []interface{}{
+			map[string]interface{}{"hostid": "1", "inventory_mode": "-1", "inventory": []string{}},
+			map[string]interface{}{"hostid": "2", "inventory_mode": "0", "inventory": map[string]string{"os": "Linux"}},
+			map[string]interface{}{"hostid": "3", "inventory_mode": 1, "inventory": map[string]string{"os": "FreeBSD", "tag": "db"}},
+			// before Zabbix 4.4 the mode is only part of the inventory
+			map[string]interface{}{"hostid": "4", "inventory": map[string]string{"inventory_mode": "1", "os": "Windows"}},
+			map[string]interface{}{"hostid": "5", "inventory": []string{}},
+		}, nil
+	})
+
+	hosts, err := api.HostsGet(zapi.Params{"selectInventory": "extend"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []zapi.InventoryMode{zapi.InventoryDisabled, zapi.InventoryManual, zapi.InventoryAutomatic, zapi.InventoryAutomatic, zapi.InventoryDisabled}
+	if len(hosts) != len(expected) {
+		t.Fatalf("Bad hosts: %#v", hosts)
+	}
+	for i, mode := range expected {
+		if hosts[i].InventoryMode != mode {
+			t.Errorf("Host %s: expected inventory mode %d, got %d", hosts[i].HostID, mode, hosts[i].InventoryMode)
+		}
+	}
+	if hosts[0].Inventory != nil || hosts[1].Inventory["os"] != "Linux" || hosts[2].Inventory["tag"] != "db" {
+		t.Errorf("Bad inventories: %#v %#v %#v", hosts[0].Inventory, hosts[1].Inventory, hosts[2].Inventory)
+	}
+	if _, ok := hosts[3].Inventory["inventory_mode"]; ok || hosts[3].Inventory["os"] != "Windows" {
+		t.Errorf("Bad inventory: %#v", hosts[3].Inventory)
+	}
+
+	b, _ := json.Marshal(zapi.Host{Host: "web01", InventoryMode: zapi.InventoryDisabled})
+	var sent map[string]interface{}
+	json.Unmarshal(b, &sent)
+	if sent["inventory_mode"] != "-1" {
+		t.Errorf("Bad inventory mode sent: %s", b)
+	}
+}
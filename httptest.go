@@ -0,0 +1,207 @@
+package zabbix
+
+import (
+	"net/url"
+	"strings"
+)
+
+type (
+	// HTTPAuthType HTTP authentication method of a web scenario
+	// see "authentication" in https://www.zabbix.com/documentation/6.0/manual/api/reference/httptest/object
+	HTTPAuthType int
+)
+
+const (
+	// HTTPAuthNone none (default)
+	HTTPAuthNone HTTPAuthType = 0
+	// HTTPAuthBasic basic
+	HTTPAuthBasic HTTPAuthType = 1
+	// HTTPAuthNTLM NTLM
+	HTTPAuthNTLM HTTPAuthType = 2
+	// HTTPAuthKerberos Kerberos
+	HTTPAuthKerberos HTTPAuthType = 3
+	// HTTPAuthDigest digest, Zabbix 5.2+
+	HTTPAuthDigest HTTPAuthType = 4
+)
+
+// HTTPField is a named value of a web scenario or step, like a query field, header or variable
+type HTTPField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HTTPFields is an array of HTTPField
+type HTTPFields []HTTPField
+
+// WebStep represent Zabbix web scenario step object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/httptest/object#scenario-step
+type WebStep struct {
+	HTTPStepID      string     `json:"httpstepid,omitempty"`
+	Name            string     `json:"name"`
+	No              string     `json:"no"`
+	URL             string     `json:"url"`
+	QueryFields     HTTPFields `json:"query_fields,omitempty"`
+	Posts           string     `json:"posts,omitempty"`
+	Variables       HTTPFields `json:"variables,omitempty"`
+	Headers         HTTPFields `json:"headers,omitempty"`
+	FollowRedirects string     `json:"follow_redirects,omitempty"`
+	RetrieveMode    string     `json:"retrieve_mode,omitempty"`
+	Timeout         string     `json:"timeout,omitempty"`
+	Required        string     `json:"required,omitempty"`
+	StatusCodes     string     `json:"status_codes,omitempty"`
+}
+
+// WebSteps is an array of WebStep
+type WebSteps []WebStep
+
+// WebScenario represent Zabbix web scenario object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/httptest/object
+type WebScenario struct {
+	HTTPTestID string     `json:"httptestid,omitempty"`
+	HostID     string     `json:"hostid,omitempty"`
+	Name       string     `json:"name"`
+	Delay      string     `json:"delay,omitempty"`
+	Retries    string     `json:"retries,omitempty"`
+	Agent      string     `json:"agent,omitempty"`
+	HTTPProxy  string     `json:"http_proxy,omitempty"`
+	Status     StatusType `json:"status,string"`
+	Variables  HTTPFields `json:"variables,omitempty"`
+	Headers    HTTPFields `json:"headers,omitempty"`
+	Tags       Tags       `json:"tags,omitempty"`
+
+	// Authentication
+	Authentication HTTPAuthType `json:"authentication,string"`
+	HTTPUser       string       `json:"http_user,omitempty"`
+	HTTPPassword   string       `json:"http_password,omitempty"`
+	VerifyPeer     string       `json:"verify_peer,omitempty"`
+	VerifyHost     string       `json:"verify_host,omitempty"`
+	SSLCertFile    string       `json:"ssl_cert_file,omitempty"`
+	SSLKeyFile     string       `json:"ssl_key_file,omitempty"`
+	SSLKeyPassword string       `json:"ssl_key_password,omitempty"`
+
+	Steps WebSteps `json:"steps,omitempty"`
+}
+
+// WebScenarios is an array of WebScenario
+type WebScenarios []WebScenario
+
+// WebScenariosGet Wrapper for httptest.get
+// Steps are selected unless asked otherwise.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/httptest/get
+func (api *API) WebScenariosGet(params Params) (res WebScenarios, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	if _, present := params["selectSteps"]; !present {
+		params["selectSteps"] = "extend"
+	}
+	err = api.CallWithErrorParse("httptest.get", params, &res)
+	return
+}
+
+// prepWebScenarios moves step query fields to the step URL on servers older
+// than 4.0, which do not know query_fields
+func (api *API) prepWebScenarios(scenarios WebScenarios) (err error) {
+	hasQuery := false
+	for _, s := range scenarios {
+		for _, step := range s.Steps {
+			hasQuery = hasQuery || len(step.QueryFields) > 0
+		}
+	}
+	if !hasQuery {
+		return
+	}
+	v, err := api.ServerVersion()
+	if err != nil || v >= 40000 {
+		return
+	}
+
+	for i := range scenarios {
+		for j, step := range scenarios[i].Steps {
+			if len(step.QueryFields) == 0 {
+				continue
+			}
+			query := make([]string, len(step.QueryFields))
+			for k, f := range step.QueryFields {
+				query[k] = url.QueryEscape(f.Name) + "=" + url.QueryEscape(f.Value)
+			}
+			sep := "?"
+			if strings.Contains(step.URL, "?") {
+				sep = "&"
+			}
+			scenarios[i].Steps[j].URL += sep + strings.Join(query, "&")
+			scenarios[i].Steps[j].QueryFields = nil
+		}
+	}
+	return
+}
+
+// WebScenariosCreate Wrapper for httptest.create
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/httptest/create
+func (api *API) WebScenariosCreate(scenarios WebScenarios) (err error) {
+	if err = api.prepWebScenarios(scenarios); err != nil {
+		return
+	}
+	response, err := api.CallWithError("httptest.create", scenarios)
+	if err != nil {
+		return
+	}
+
+	httptestids, err := resultIDs(response, "httptestids")
+	if err != nil {
+		return
+	}
+	if len(httptestids) != len(scenarios) {
+		return &ExpectedMore{len(scenarios), len(httptestids)}
+	}
+	for i, id := range httptestids {
+		scenarios[i].HTTPTestID = id
+	}
+	return
+}
+
+// WebScenariosUpdate Wrapper for httptest.update
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/httptest/update
+func (api *API) WebScenariosUpdate(scenarios WebScenarios) (err error) {
+	if err = api.prepWebScenarios(scenarios); err != nil {
+		return
+	}
+	_, err = api.CallWithError("httptest.update", scenarios)
+	return
+}
+
+// WebScenariosDelete Wrapper for httptest.delete
+// Cleans HTTPTestID in all scenarios elements if call succeed.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/httptest/delete
+func (api *API) WebScenariosDelete(scenarios WebScenarios) (err error) {
+	ids := make([]string, len(scenarios))
+	for i, scenario := range scenarios {
+		ids[i] = scenario.HTTPTestID
+	}
+
+	err = api.WebScenariosDeleteByIds(ids)
+	if err == nil {
+		for i := range scenarios {
+			scenarios[i].HTTPTestID = ""
+		}
+	}
+	return
+}
+
+// WebScenariosDeleteByIds Wrapper for httptest.delete
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/httptest/delete
+func (api *API) WebScenariosDeleteByIds(ids []string) (err error) {
+	response, err := api.CallWithError("httptest.delete", ids)
+	if err != nil {
+		return
+	}
+
+	httptestids, err := resultIDs(response, "httptestids")
+	if err != nil {
+		return
+	}
+	if len(ids) != len(httptestids) {
+		err = &ExpectedMore{len(ids), len(httptestids)}
+	}
+	return
+}
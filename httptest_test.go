@@ -0,0 +1,75 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestWebScenariosCreateAuthenticated(t *testing.T) {
+	var sent []map[string]interface{}
+	var steps []zapi.WebStep
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method == "apiinfo.version" {
+			return "6.0.21", nil
+		}
+		var s []struct {
+			Steps []zapi.WebStep `json:"steps"`
+		}
+		json.Unmarshal(params, &sent)
+		json.Unmarshal(params, &s)
+		steps = s[0].Steps
+		return map[string][]string{"httptestids": {"5"}}, nil
+	})
+
+	newScenarios := func() zapi.WebScenarios {
+		return zapi.WebScenarios{{
+			HostID:         "10084",
+			Name:           "Admin login",
+			Authentication: zapi.HTTPAuthBasic,
+			HTTPUser:       "monitor",
+			HTTPPassword:   "{$WEB_PASSWORD}",
+			VerifyPeer:     "1",
+			VerifyHost:     "1",
+			SSLCertFile:    "client.pem",
+			Steps: zapi.WebSteps{{
+				Name:        "Dashboard",
+				No:          "1",
+				URL:         "https://example.com/admin",
+				QueryFields: zapi.HTTPFields{{Name: "view", Value: "summary"}},
+				StatusCodes: "200",
+			}},
+		}}
+	}
+
+	scenarios := newScenarios()
+	if err := api.WebScenariosCreate(scenarios); err != nil {
+		t.Fatal(err)
+	}
+	if scenarios[0].HTTPTestID != "5" {
+		t.Errorf("Scenario id not filled: %#v", scenarios[0])
+	}
+	s := sent[0]
+	if s["authentication"] != "1" || s["http_user"] != "monitor" || s["verify_peer"] != "1" || s["ssl_cert_file"] != "client.pem" {
+		t.Errorf("Bad scenario sent: %#v", s)
+	}
+	if len(steps) != 1 || len(steps[0].QueryFields) != 1 || steps[0].URL != "https://example.com/admin" {
+		t.Errorf("Bad step sent: %#v", steps)
+	}
+
+	api = getMockAPI(t, zapi.Config{Version: 30400}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var s []struct {
+			Steps []zapi.WebStep `json:"steps"`
+		}
+		json.Unmarshal(params, &s)
+		steps = s[0].Steps
+		return map[string][]string{"httptestids": {"6"}}, nil
+	})
+	if err := api.WebScenariosCreate(newScenarios()); err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 1 || len(steps[0].QueryFields) != 0 || steps[0].URL != "https://example.com/admin?view=summary" {
+		t.Errorf("Query fields not moved to the URL before 4.0: %#v", steps)
+	}
+}
@@ -2,3 +2,23 @@ package zabbix
 
 // https://www.zabbix.com/documentation/5.0/manual/api/reference/host/object#host_inventory
 type Inventory map[string]string
+
+// HostsInventory Gets the inventory of the hosts by host id, of all hosts when hostIDs is empty.
+// Hosts with inventory disabled, returned with an empty inventory, are left out.
+func (api *API) HostsInventory(hostIDs []string) (res map[string]Inventory, err error) {
+	params := Params{"output": []string{"hostid"}, "selectInventory": "extend"}
+	if len(hostIDs) > 0 {
+		params["hostids"] = hostIDs
+	}
+	hosts, err := api.HostsGet(params)
+	if err != nil {
+		return
+	}
+	res = make(map[string]Inventory, len(hosts))
+	for _, host := range hosts {
+		if len(host.Inventory) > 0 {
+			res[host.HostID] = host.Inventory
+		}
+	}
+	return
+}
@@ -3,6 +3,10 @@ package zabbix
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type (
@@ -14,6 +18,8 @@ type (
 	DataType int
 	// DeltaType value that will be stored
 	DeltaType int
+	// ItemState (readonly) state of the item
+	ItemState int
 )
 
 const (
@@ -59,6 +65,10 @@ const (
 	Dependent ItemType = 18
 	HTTPAgent ItemType = 19
 	SNMPAgent ItemType = 20
+	// Script type, Zabbix 6.0+
+	Script ItemType = 21
+	// Browser type, Zabbix 7.0+, see BrowserItem
+	Browser ItemType = 22
 )
 
 const (
@@ -103,27 +113,45 @@ const (
 	Delta DeltaType = 2
 )
 
+const (
+	// State of the item
+	// see "state" in https://www.zabbix.com/documentation/3.2/manual/api/reference/item/object
+
+	// ItemStateNormal normal (default)
+	ItemStateNormal ItemState = 0
+	// ItemStateNotSupported not supported
+	ItemStateNotSupported ItemState = 1
+)
+
 type HttpHeaders map[string]string
 
 // Item represent Zabbix item object
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/object
 type Item struct {
-	ItemID       string    `json:"itemid,omitempty"`
-	Delay        string    `json:"delay"`
-	HostID       string    `json:"hostid"`
-	InterfaceID  string    `json:"interfaceid,omitempty"`
-	Key          string    `json:"key_"`
-	Name         string    `json:"name"`
-	Type         ItemType  `json:"type,string"`
-	ValueType    ValueType `json:"value_type,string"`
-	DataType     DataType  `json:"data_type,string"`
-	Delta        DeltaType `json:"delta,string"`
-	Description  string    `json:"description"`
-	Error        string    `json:"error,omitempty"`
-	History      string    `json:"history,omitempty"`
-	Trends       string    `json:"trends,omitempty"`
-	TrapperHosts string    `json:"trapper_hosts,omitempty"`
-	Params       string    `json:"params,omitempty"`
+	ItemID       string     `json:"itemid,omitempty"`
+	UUID         string     `json:"uuid,omitempty"`
+	TemplateID   string     `json:"templateid,omitempty"` // read only
+	Delay        string     `json:"delay"`
+	HostID       string     `json:"hostid"`
+	InterfaceID  string     `json:"interfaceid,omitempty"`
+	Key          string     `json:"key_"`
+	Name         string     `json:"name"`
+	Type         ItemType   `json:"type,string"`
+	ValueType    ValueType  `json:"value_type,string"`
+	DataType     DataType   `json:"data_type,string"`
+	Delta        DeltaType  `json:"delta,string"`
+	Description  string     `json:"description"`
+	Status       StatusType `json:"status,string"`
+	State        ItemState  `json:"state,omitempty,string"`
+	Error        string     `json:"error,omitempty"`
+	History      string     `json:"history,omitempty"`
+	Trends       string     `json:"trends,omitempty"`
+	Units        string     `json:"units,omitempty"`
+	ValueMapID   string     `json:"valuemapid,omitempty"` // "0" removes the value map
+	TrapperHosts string     `json:"trapper_hosts,omitempty"`
+	Params       string     `json:"params,omitempty"`
+	// Parameters passed to the script of Script items
+	Parameters ItemParameters `json:"parameters,omitempty"`
 
 	// list of strings on set, but list of objects on get
 	RawApplications json.RawMessage `json:"applications,omitempty"`
@@ -132,6 +160,16 @@ type Item struct {
 	ItemParent Hosts `json:"hosts"`
 
 	Preprocessors Preprocessors `json:"preprocessing,omitempty"`
+	Tags          Tags          `json:"tags,omitempty"`
+	// ValueMap is read only, filled by ItemGetOptions.SelectValueMap
+	ValueMap *ValueMap `json:"valuemap,omitempty"`
+
+	// Last values are read only, see ItemGetOptions.WithLastValue
+	LastValue string `json:"lastvalue,omitempty"`
+	LastClock string `json:"lastclock,omitempty"`
+	PrevValue string `json:"prevvalue,omitempty"`
+	// Triggers is read only, filled by ItemGetOptions.SelectTriggers
+	Triggers Triggers `json:"triggers,omitempty"`
 
 	// HTTP Agent Fields
 	Url           string          `json:"url,omitempty"`
@@ -174,8 +212,28 @@ type Preprocessor struct {
 	Params             string `json:"params"`
 	ErrorHandler       string `json:"error_handler,omitempty"`
 	ErrorHandlerParams string `json:"error_handler_params"`
+	// SortOrder is read only, position of the step when returned by the server
+	SortOrder string `json:"sortorder,omitempty"`
+}
+
+// sorted Orders the steps by SortOrder, steps without one keep their position
+func (p Preprocessors) sorted() {
+	sort.SliceStable(p, func(i, j int) bool {
+		a, errA := strconv.Atoi(p[i].SortOrder)
+		b, errB := strconv.Atoi(p[j].SortOrder)
+		return errA == nil && errB == nil && a < b
+	})
+}
+
+// ItemParameter is a named parameter of a Script item
+type ItemParameter struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
+// ItemParameters is an array of ItemParameter
+type ItemParameters []ItemParameter
+
 // Items is an array of Item
 type Items []Item
 
@@ -192,6 +250,162 @@ func (items Items) ByKey() (res map[string]Item) {
 	return
 }
 
+// ValidateStoragePeriods Checks the History and Trends periods of the item.
+// Periods must be Zabbix durations, values containing a macro are not checked.
+// Trends can only be stored for numeric items.
+func ValidateStoragePeriods(item Item) error {
+	for _, period := range []struct{ name, value string }{{"history", item.History}, {"trends", item.Trends}} {
+		if period.value == "" || strings.Contains(period.value, "{") {
+			continue
+		}
+		if _, err := parseDuration(period.value); err != nil {
+			return fmt.Errorf("Item %s: bad %s period: %s", item.Key, period.name, err)
+		}
+	}
+
+	if item.ValueType == Float || item.ValueType == Unsigned {
+		return nil
+	}
+	if item.Trends != "" && item.Trends != "0" {
+		return fmt.Errorf("Item %s: trends can not be stored for non numeric value type %d", item.Key, item.ValueType)
+	}
+	return nil
+}
+
+// ResolveItemDelay Returns the base update interval of the item, after expanding the user
+// macros of delay with the macros effective on the host. Custom intervals are checked but
+// ignored, the base interval is zero for items only polled on schedule.
+func (api *API) ResolveItemDelay(item Item, hostID string) (time.Duration, error) {
+	delay := item.Delay
+	if strings.Contains(delay, "{$") {
+		macros, err := api.EffectiveMacros(hostID)
+		if err != nil {
+			return 0, err
+		}
+		if delay, err = expandUserMacros(delay, macros); err != nil {
+			return 0, err
+		}
+	}
+	d, err := parseItemDelay(delay)
+	if err != nil {
+		return 0, fmt.Errorf("Item %s: bad delay %q: %s", item.Key, item.Delay, err)
+	}
+	return d, nil
+}
+
+// defaultItemTimeout is the timeout of HTTP agent and script items before Zabbix 7.0
+const defaultItemTimeout = "3s"
+
+// itemTimeoutSupported reports whether items of type t take a timeout on server version v
+func itemTimeoutSupported(t ItemType, v int) bool {
+	switch t {
+	case HTTPAgent, Script:
+		return true
+	case ZabbixAgent, ZabbixAgentActive, SimpleCheck, SNMPAgent, ExternalCheck, DatabaseMonitor, SSHAgent, TELNETAgent, Browser:
+		return v >= 70000
+	}
+	return false
+}
+
+// parseItemTimeout parses the timeout of the item, checking the range allowed by server version v
+func parseItemTimeout(item Item, timeout string, v int) (time.Duration, error) {
+	max := time.Minute
+	if v >= 70000 {
+		max = 10 * time.Minute
+	}
+	d, err := parseDuration(timeout)
+	if err == nil && (d < time.Second || d > max) {
+		err = fmt.Errorf("Timeout out of range 1s-%ds", int(max.Seconds()))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("Item %s: bad timeout %q: %s", item.Key, item.Timeout, err)
+	}
+	return d, nil
+}
+
+// ResolveItemTimeout Returns the timeout of the item, user macros being resolved
+// on hostID. Zero is returned for items without timeout and, on Zabbix 7.0+,
+// for items using the proxy or global default.
+func (api *API) ResolveItemTimeout(item Item, hostID string) (time.Duration, error) {
+	v, err := api.ServerVersion()
+	if err != nil {
+		return 0, err
+	}
+	if !itemTimeoutSupported(item.Type, v) {
+		return 0, nil
+	}
+	timeout := item.Timeout
+	if timeout == "" {
+		if v >= 70000 {
+			return 0, nil
+		}
+		timeout = defaultItemTimeout
+	}
+	if strings.Contains(timeout, "{$") {
+		macros, err := api.EffectiveMacros(hostID)
+		if err != nil {
+			return 0, err
+		}
+		if timeout, err = expandUserMacros(timeout, macros); err != nil {
+			return 0, err
+		}
+	}
+	return parseItemTimeout(item, timeout, v)
+}
+
+// prepItemTimeouts adapts the item timeouts to the server version. Timeouts are dropped
+// from item types not taking one and, on create, HTTP agent and script items get the
+// default timeout before Zabbix 7.0, later versions using the proxy or global one.
+// Timeouts are checked, user macros being resolved on the item host when they are all
+// defined. Items are left as they are when the version is unknown.
+func (api *API) prepItemTimeouts(items Items, create bool) error {
+	needed := false
+	for _, item := range items {
+		needed = needed || item.Timeout != "" || (create && (item.Type == HTTPAgent || item.Type == Script))
+	}
+	if !needed {
+		return nil
+	}
+	v, err := api.ServerVersion()
+	if err != nil {
+		return nil
+	}
+
+	macros := map[string]map[string]string{}
+	for i, item := range items {
+		if !itemTimeoutSupported(item.Type, v) {
+			items[i].Timeout = ""
+			continue
+		}
+		timeout := item.Timeout
+		switch {
+		case timeout == "":
+			if create && v < 70000 {
+				items[i].Timeout = defaultItemTimeout
+			}
+			continue
+		case strings.Contains(timeout, "{#"):
+			continue
+		case strings.Contains(timeout, "{$"):
+			if item.HostID == "" {
+				continue
+			}
+			if _, ok := macros[item.HostID]; !ok {
+				if macros[item.HostID], err = api.EffectiveMacros(item.HostID); err != nil {
+					return err
+				}
+			}
+			if timeout, err = expandUserMacros(timeout, macros[item.HostID]); err != nil {
+				continue
+			}
+		}
+		if _, err = parseItemTimeout(item, timeout, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ItemsGet Wrapper for item.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/get
 func (api *API) ItemsGet(params Params) (res Items, err error) {
@@ -200,8 +414,214 @@ func (api *API) ItemsGet(params Params) (res Items, err error) {
 	}
 	err = api.CallWithErrorParse("item.get", params, &res)
 	api.itemsHeadersUnmarshal(res)
+	if err == nil && outputs(params["output"], "value_type") {
+		for _, item := range res {
+			api.valueTypes.Store(item.ItemID, item.ValueType)
+		}
+	}
 	return
 }
+
+// outputs reports whether output selects field
+func outputs(output interface{}, field string) bool {
+	switch o := output.(type) {
+	case string:
+		return o == "extend"
+	case []string:
+		for _, f := range o {
+			if f == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// forgetValueTypes drops the cached value type of the items
+func (api *API) forgetValueTypes(ids []string) {
+	for _, id := range ids {
+		api.valueTypes.Delete(id)
+	}
+}
+
+// ItemValueType Gets the value type of the item, from the types of the items
+// already read by ItemsGet when possible. Item updates and deletions through
+// this package drop the cached type.
+func (api *API) ItemValueType(itemID string) (res ValueType, err error) {
+	if v, ok := api.valueTypes.Load(itemID); ok {
+		return v.(ValueType), nil
+	}
+	items, err := api.ItemsGet(Params{"itemids": itemID, "output": []string{"itemid", "value_type"}})
+	if err != nil {
+		return
+	}
+	if len(items) != 1 {
+		e := ExpectedOneResult(len(items))
+		err = &e
+		return
+	}
+	return items[0].ValueType, nil
+}
+
+// ItemGetOptions typed parameters for item.get
+type ItemGetOptions struct {
+	GetOptions
+	ItemIDs     []string
+	HostIDs     []string
+	GroupIDs    []string
+	TemplateIDs []string
+	// Status and State filter the items when set
+	Status *StatusType
+	State  *ItemState
+	// SelectValueMap fills ValueMap
+	SelectValueMap bool
+	// SelectTriggers fills Triggers with the triggers using the item
+	SelectTriggers bool
+	// SelectPreprocessing fills Preprocessors, in the order of the steps
+	SelectPreprocessing bool
+	// WithLastValue fills LastValue, LastClock and PrevValue,
+	// from history when the server does not return them
+	WithLastValue bool
+}
+
+func (o ItemGetOptions) params() Params {
+	params := o.GetOptions.params()
+	params.setIDs("itemids", o.ItemIDs)
+	params.setIDs("hostids", o.HostIDs)
+	params.setIDs("groupids", o.GroupIDs)
+	params.setIDs("templateids", o.TemplateIDs)
+	if o.Status != nil {
+		params.setFilter("status", *o.Status)
+	}
+	if o.State != nil {
+		params.setFilter("state", *o.State)
+	}
+	if o.SelectValueMap {
+		params["selectValueMap"] = "extend"
+	}
+	if o.SelectTriggers {
+		params["selectTriggers"] = []string{"triggerid", "description", "priority", "status", "value", "lastchange"}
+	}
+	if o.SelectPreprocessing {
+		params["selectPreprocessing"] = "extend"
+	}
+	if output, ok := o.Output.([]string); ok && o.WithLastValue {
+		params["output"] = append(output[:len(output):len(output)], "itemid", "value_type", "lastvalue", "lastclock", "prevvalue")
+	}
+	return params
+}
+
+// ItemsGetWithOptions Wrapper for item.get using typed options
+func (api *API) ItemsGetWithOptions(opts ItemGetOptions) (res Items, err error) {
+	defer func() {
+		if err == nil {
+			err = opts.truncated(len(res))
+		}
+	}()
+	res, err = api.ItemsGet(opts.params())
+	if err == nil && opts.SelectPreprocessing {
+		for _, item := range res {
+			item.Preprocessors.sorted()
+		}
+	}
+	if err != nil || !opts.WithLastValue {
+		return
+	}
+
+	// servers not returning last values leave lastclock out, "0" means no data
+	missing := Items{}
+	index := []int{}
+	for i, item := range res {
+		if item.LastClock == "" {
+			missing = append(missing, item)
+			index = append(index, i)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	if err = api.fillLastValues(missing); err != nil {
+		return
+	}
+	for j, i := range index {
+		res[i] = missing[j]
+	}
+	return
+}
+
+// ItemsGetNotSupported Gets enabled items of the host in not supported state, Error holds the reason.
+func (api *API) ItemsGetNotSupported(hostID string) (res Items, err error) {
+	status, state := Enabled, ItemStateNotSupported
+	return api.ItemsGetWithOptions(ItemGetOptions{
+		HostIDs: []string{hostID},
+		Status:  &status,
+		State:   &state,
+	})
+}
+
+// ItemWithTriggerState Gets the item with the triggers using it, their Value telling
+// whether they are firing and ProblemCount their number of unresolved problems.
+func (api *API) ItemWithTriggerState(itemID string) (res *Item, err error) {
+	items, err := api.ItemsGetWithOptions(ItemGetOptions{
+		ItemIDs:        []string{itemID},
+		SelectTriggers: true,
+	})
+	if err != nil {
+		return
+	}
+	if len(items) != 1 {
+		e := ExpectedOneResult(len(items))
+		err = &e
+		return
+	}
+	res = &items[0]
+	if len(res.Triggers) == 0 {
+		return
+	}
+
+	ids := make([]string, len(res.Triggers))
+	for i, t := range res.Triggers {
+		ids[i] = t.TriggerID
+	}
+	res.Triggers, err = api.TriggersGetWithOptions(TriggerGetOptions{
+		GetOptions:       GetOptions{Output: []string{"triggerid", "description", "priority", "status", "value", "lastchange"}},
+		TriggerIDs:       ids,
+		WithProblemCount: true,
+	})
+	return
+}
+
+// ItemHealth describes an item which is not in the normal state
+type ItemHealth struct {
+	ItemID string
+	HostID string
+	Key    string
+	Name   string
+	State  ItemState
+	// Error is the reason reported by the server
+	Error string
+}
+
+// ItemsHealthReport Gets the enabled items of the hosts which are not supported, along with the reason.
+func (api *API) ItemsHealthReport(hostIDs []string) (res []ItemHealth, err error) {
+	status, state := Enabled, ItemStateNotSupported
+	items, err := api.ItemsGetWithOptions(ItemGetOptions{
+		GetOptions: GetOptions{Output: []string{"itemid", "hostid", "key_", "name", "state", "error"}},
+		HostIDs:    hostIDs,
+		Status:     &status,
+		State:      &state,
+	})
+	if err != nil {
+		return
+	}
+
+	res = make([]ItemHealth, len(items))
+	for i, item := range items {
+		res[i] = ItemHealth{item.ItemID, item.HostID, item.Key, item.Name, item.State, item.Error}
+	}
+	return
+}
+
 func (api *API) ProtoItemsGet(params Params) (res Items, err error) {
 	if _, present := params["output"]; !present {
 		params["output"] = "extend"
@@ -252,10 +672,34 @@ func (api *API) itemsHeadersUnmarshal(item Items) {
 	}
 }
 
+// validateItems checks the items before they are sent
+func validateItems(items Items) error {
+	for _, item := range items {
+		if item.Type == Script && item.Params == "" {
+			return fmt.Errorf("Item %s: Script items require a script in Params", item.Key)
+		}
+		if item.Type == Calculated {
+			if err := ValidateCalculatedFormula(item.Params); err != nil {
+				return fmt.Errorf("Item %s: %s", item.Key, err)
+			}
+		}
+	}
+	return nil
+}
+
 func prepItems(item Items) {
 	for i := 0; i < len(item); i++ {
 		h := item[i]
 
+		// read only
+		item[i].ValueMap = nil
+		item[i].LastValue, item[i].LastClock, item[i].PrevValue = "", "", ""
+		item[i].Triggers = nil
+		item[i].TemplateID = ""
+		for j := range item[i].Preprocessors {
+			item[i].Preprocessors[j].SortOrder = ""
+		}
+
 		if h.Applications != nil {
 			text, _ := json.Marshal(h.Applications)
 			raw := json.RawMessage(text)
@@ -300,6 +744,25 @@ func (api *API) ProtoItemGetByID(id string) (res *Item, err error) {
 	return
 }
 
+// ItemMasterChain Gets the item and the master items it depends on, directly or not,
+// ordered from the root master to the item. Fails on a dependency loop.
+func (api *API) ItemMasterChain(itemID string) (res Items, err error) {
+	seen := map[string]bool{}
+	for id := itemID; id != "" && id != "0"; {
+		if seen[id] {
+			return nil, fmt.Errorf("Item %s: master items depend on each other in a loop at %s", itemID, id)
+		}
+		seen[id] = true
+		item, err := api.ItemGetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		res = append(Items{*item}, res...)
+		id = item.MasterItemID
+	}
+	return
+}
+
 // ItemsGetByApplicationID Gets items by application Id.
 func (api *API) ItemsGetByApplicationID(id string) (res Items, err error) {
 	return api.ItemsGet(Params{"applicationids": id})
@@ -309,8 +772,18 @@ func (api *API) ProtoItemsGetByApplicationID(id string) (res Items, err error) {
 }
 
 // ItemsCreate Wrapper for item.create
+// Template items missing an UUID get one on Zabbix 6.0+, timeouts are adapted to the version.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/create
 func (api *API) ItemsCreate(items Items) (err error) {
+	if err = validateItems(items); err != nil {
+		return
+	}
+	if err = api.prepItemTimeouts(items, true); err != nil {
+		return
+	}
+	if err = api.fillItemUUIDs(items); err != nil {
+		return
+	}
 	prepItems(items)
 	response, err := api.CallWithError("item.create", items)
 	if err != nil {
@@ -325,6 +798,15 @@ func (api *API) ItemsCreate(items Items) (err error) {
 	return
 }
 func (api *API) ProtoItemsCreate(items Items) (err error) {
+	if err = validateItems(items); err != nil {
+		return
+	}
+	if err = api.prepItemTimeouts(items, true); err != nil {
+		return
+	}
+	if err = api.fillItemUUIDs(items); err != nil {
+		return
+	}
 	prepItems(items)
 	response, err := api.CallWithError("itemprototype.create", items)
 	if err != nil {
@@ -339,19 +821,185 @@ func (api *API) ProtoItemsCreate(items Items) (err error) {
 	return
 }
 
+// itemInterfaceType returns the interface type the item type polls through,
+// any is set when any type fits and needed is false when the item has no interface
+func itemInterfaceType(t ItemType) (it InterfaceType, any, needed bool) {
+	switch t {
+	case ZabbixAgent:
+		return Agent, false, true
+	case SNMPv1Agent, SNMPv2Agent, SNMPv3Agent, SNMPTrap, SNMPAgent:
+		return SNMP, false, true
+	case IPMIAgent:
+		return IPMI, false, true
+	case JMXAgent:
+		return JMX, false, true
+	case SimpleCheck, ExternalCheck, SSHAgent, TELNETAgent:
+		return Agent, true, true
+	}
+	return "", false, false
+}
+
+// ItemsCreateAutoInterface Creates the items on the host, filling missing InterfaceID
+// with the main host interface matching the item type. Simple checks, external checks,
+// SSH and Telnet items use the main agent interface, or any main one.
+func (api *API) ItemsCreateAutoInterface(hostID string, items Items) (err error) {
+	hosts, err := api.HostsGetWithOptions(HostGetOptions{
+		GetOptions:       GetOptions{Output: []string{"hostid", "host"}},
+		HostIDs:          []string{hostID},
+		SelectInterfaces: true,
+	})
+	if err != nil {
+		return
+	}
+	if len(hosts) != 1 {
+		e := ExpectedOneResult(len(hosts))
+		return &e
+	}
+
+	main := newMainInterfaces(hosts[0].Interfaces)
+	for i, item := range items {
+		items[i].HostID = hostID
+		if item.InterfaceID != "" {
+			continue
+		}
+		id, ok := main.forType(item.Type)
+		if !ok {
+			it, _, _ := itemInterfaceType(item.Type)
+			return fmt.Errorf("Host %s has no main interface of type %s for item %s", hosts[0].Host, it, item.Key)
+		}
+		items[i].InterfaceID = id
+	}
+	return api.ItemsCreate(items)
+}
+
+// mainInterfaces are the ids of the main interfaces of a host by type,
+// fallback being the first of them
+type mainInterfaces struct {
+	byType   map[InterfaceType]string
+	fallback string
+}
+
+func newMainInterfaces(interfaces HostInterfaces) (res mainInterfaces) {
+	res.byType = map[InterfaceType]string{}
+	for _, in := range interfaces {
+		if in.Main != "1" {
+			continue
+		}
+		res.byType[in.Type] = in.InterfaceID
+		if res.fallback == "" {
+			res.fallback = in.InterfaceID
+		}
+	}
+	return
+}
+
+// forType returns the interface items of type t poll through, empty when
+// they need none, ok is false when there is no suitable one
+func (m mainInterfaces) forType(t ItemType) (id string, ok bool) {
+	it, any, needed := itemInterfaceType(t)
+	if !needed {
+		return "", true
+	}
+	id, ok = m.byType[it]
+	if !ok && any {
+		id, ok = m.fallback, m.fallback != ""
+	}
+	return
+}
+
+// ItemsCreateOrdered Creates items with their dependent items in one go.
+// MasterItemID of a dependent item may be the key of a master item of the same
+// host in items, masters are then created first and their id set on dependents.
+// Items are created level by level, one item.create call per level.
+func (api *API) ItemsCreateOrdered(items Items) (err error) {
+	byKey := map[string]int{}
+	for i, item := range items {
+		byKey[item.HostID+"/"+item.Key] = i
+	}
+	master := make([]int, len(items))
+	for i, item := range items {
+		master[i] = -1
+		if j, ok := byKey[item.HostID+"/"+item.MasterItemID]; ok && item.MasterItemID != "" && j != i {
+			master[i] = j
+		}
+	}
+
+	created := make([]bool, len(items))
+	for remaining := len(items); remaining > 0; {
+		level := Items{}
+		index := []int{}
+		for i := range items {
+			if created[i] || master[i] != -1 && !created[master[i]] {
+				continue
+			}
+			if master[i] != -1 {
+				items[i].MasterItemID = items[master[i]].ItemID
+			}
+			level = append(level, items[i])
+			index = append(index, i)
+		}
+		if len(level) == 0 {
+			keys := []string{}
+			for i, item := range items {
+				if !created[i] {
+					keys = append(keys, item.Key)
+				}
+			}
+			return fmt.Errorf("Cyclic master items for %s", strings.Join(keys, ", "))
+		}
+
+		if err = api.ItemsCreate(level); err != nil {
+			return
+		}
+		for j, i := range index {
+			items[i] = level[j]
+			created[i] = true
+		}
+		remaining -= len(level)
+	}
+	return
+}
+
 // ItemsUpdate Wrapper for item.update
+// Tags templated items share with their template item are not sent.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/update
 func (api *API) ItemsUpdate(items Items) (err error) {
+	if err = validateItems(items); err != nil {
+		return
+	}
+	if err = api.prepItemTimeouts(items, false); err != nil {
+		return
+	}
+	if err = api.StripInheritedTags(items); err != nil {
+		return
+	}
 	prepItems(items)
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ItemID
+	}
+	api.forgetValueTypes(ids)
 	_, err = api.CallWithError("item.update", items)
 	return
 }
 func (api *API) ProtoItemsUpdate(items Items) (err error) {
+	if err = validateItems(items); err != nil {
+		return
+	}
+	if err = api.prepItemTimeouts(items, false); err != nil {
+		return
+	}
 	prepItems(items)
 	_, err = api.CallWithError("itemprototype.update", items)
 	return
 }
 
+// UpdateItemFields Wrapper for item.update sending only the given fields and the item id.
+func (api *API) UpdateItemFields(itemID string, fields map[string]interface{}) (err error) {
+	api.forgetValueTypes([]string{itemID})
+	return api.updateFields("item.update", "itemid", itemID, fields)
+}
+
 // ItemsDelete Wrapper for item.delete
 // Cleans ItemId in all items elements if call succeed.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/delete
@@ -412,6 +1060,7 @@ func (api *API) ProtoItemsDeleteByIds(ids []string) (err error) {
 // ItemsDeleteIDs Wrapper for item.delete
 // Delete the item and return the id of the deleted item
 func (api *API) ItemsDeleteIDs(ids []string) (itemids []interface{}, err error) {
+	api.forgetValueTypes(ids)
 	response, err := api.CallWithError("item.delete", ids)
 	if err != nil {
 		return
@@ -447,3 +1096,32 @@ func (api *API) ProtoItemsDeleteIDs(ids []string) (itemids []interface{}, err er
 	}
 	return
 }
+
+// ItemsAddTag Adds tag to the items, keeping their existing tags.
+func (api *API) ItemsAddTag(itemIDs []string, tag, value string) (err error) {
+	return api.itemsEditTags(itemIDs, func(tags Tags) Tags { return tags.with(tag, value) })
+}
+
+// ItemsRemoveTag Removes tag from the items, any value matches if value is empty.
+func (api *API) ItemsRemoveTag(itemIDs []string, tag, value string) (err error) {
+	return api.itemsEditTags(itemIDs, func(tags Tags) Tags { return tags.without(tag, value) })
+}
+
+func (api *API) itemsEditTags(itemIDs []string, edit func(Tags) Tags) (err error) {
+	items, err := api.ItemsGet(Params{
+		"itemids":    itemIDs,
+		"output":     []string{"itemid"},
+		"selectTags": "extend",
+	})
+	if err != nil {
+		return
+	}
+
+	ids := make([]string, len(items))
+	tags := make([]Tags, len(items))
+	for i, item := range items {
+		ids[i] = item.ItemID
+		tags[i] = edit(item.Tags)
+	}
+	return api.updateTags("item.update", "itemid", ids, tags)
+}
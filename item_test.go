@@ -1,7 +1,11 @@
 package zabbix_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	zapi "github.com/tpretz/go-zabbix-api"
 )
@@ -62,3 +66,667 @@ func TestItems(t *testing.T) {
 
 	DeleteItem(item, t)
 }
+
+func TestItemsAddTag(t *testing.T) {
+	var sent []zapi.Item
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "item.get":
+			return []zapi.Item{{ItemID: "1", Tags: zapi.Tags{{Tag: "scope", Value: "cpu"}}}}, nil
+		case "item.update":
+			json.Unmarshal(params, &sent)
+		}
+		return map[string][]string{"itemids": {"1"}}, nil
+	})
+
+	if err := api.ItemsAddTag([]string{"1"}, "team", "ops"); err != nil {
+		t.Fatal(err)
+	}
+	expected := zapi.Tags{{Tag: "scope", Value: "cpu"}, {Tag: "team", Value: "ops"}}
+	if len(sent) != 1 || !reflect.DeepEqual(sent[0].Tags, expected) {
+		t.Errorf("Tags were not merged: %#v", sent)
+	}
+}
+
+func TestItemsAddTagChunks(t *testing.T) {
+	var chunks [][]zapi.Item
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "item.get":
+			items := []zapi.Item{}
+			for i := 0; i < 250; i++ {
+				items = append(items, zapi.Item{ItemID: fmt.Sprint(i), Tags: zapi.Tags{{Tag: "index", Value: fmt.Sprint(i)}}})
+			}
+			return items, nil
+		case "item.update":
+			var sent []zapi.Item
+			json.Unmarshal(params, &sent)
+			chunks = append(chunks, sent)
+		}
+		return map[string][]string{"itemids": {}}, nil
+	})
+
+	if err := api.ItemsAddTag([]string{"0"}, "team", "ops"); err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 3 || len(chunks[0]) != 100 || len(chunks[1]) != 100 || len(chunks[2]) != 50 {
+		t.Fatalf("Bad chunks sent: %d", len(chunks))
+	}
+	for _, chunk := range chunks {
+		for _, item := range chunk {
+			if !item.Tags.Has("index", item.ItemID) || !item.Tags.Has("team", "ops") {
+				t.Errorf("Bad tags for item %s: %#v", item.ItemID, item.Tags)
+			}
+		}
+	}
+}
+
+func TestItemsGetNotSupported(t *testing.T) {
+	var sent struct {
+		HostIDs []string       `json:"hostids"`
+		Filter  map[string]int `json:"filter"`
+	}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return []map[string]string{{"itemid": "1", "state": "1", "error": "Unsupported item key."}}, nil
+	})
+
+	items, err := api.ItemsGetNotSupported("42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.HostIDs[0] != "42" || sent.Filter["state"] != 1 || sent.Filter["status"] != 0 {
+		t.Errorf("Bad params: %#v", sent)
+	}
+	if len(items) != 1 || items[0].State != zapi.ItemStateNotSupported || items[0].Error == "" {
+		t.Errorf("Bad items: %#v", items)
+	}
+}
+
+func TestItemsGetValueMap(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return []map[string]interface{}{{
+			"itemid": "1",
+			"valuemap": map[string]interface{}{
+				"valuemapid": "5",
+				"name":       "Service state",
+				"mappings":   []map[string]string{{"type": "0", "value": "0", "newvalue": "Down"}, {"type": "0", "value": "1", "newvalue": "Up"}},
+			},
+		}}, nil
+	})
+
+	items, err := api.ItemsGetWithOptions(zapi.ItemGetOptions{ItemIDs: []string{"1"}, SelectValueMap: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ValueMap == nil || items[0].ValueMap.NewValue("1") != "Up" {
+		t.Errorf("Bad items: %#v", items)
+	}
+}
+
+func TestItemsHealthReport(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return []map[string]string{
+			{"itemid": "1", "hostid": "42", "key_": "vfs.fs.size[/data]", "name": "Data size", "state": "1", "error": "Cannot obtain filesystem information."},
+		}, nil
+	})
+
+	report, err := api.ItemsHealthReport([]string{"42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []zapi.ItemHealth{{"1", "42", "vfs.fs.size[/data]", "Data size", zapi.ItemStateNotSupported, "Cannot obtain filesystem information."}}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Bad report: %#v", report)
+	}
+}
+
+func TestValidateStoragePeriods(t *testing.T) {
+	text := zapi.Item{Key: "log.text", ValueType: zapi.Text, History: "7d", Trends: "365d"}
+	if err := zapi.ValidateStoragePeriods(text); err == nil {
+		t.Error("Expected error for trends on text item")
+	}
+	text.Trends = "0"
+	if err := zapi.ValidateStoragePeriods(text); err != nil {
+		t.Error(err)
+	}
+
+	numeric := zapi.Item{Key: "system.cpu.load", ValueType: zapi.Float, History: "90d", Trends: "{$TRENDS}"}
+	if err := zapi.ValidateStoragePeriods(numeric); err != nil {
+		t.Error(err)
+	}
+	numeric.History = "90x"
+	if err := zapi.ValidateStoragePeriods(numeric); err == nil {
+		t.Error("Expected error for bad history period")
+	}
+}
+
+func TestItemsCreateScript(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{Version: 60000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method == "template.get" {
+			return []interface{}{}, nil
+		}
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"itemids": {"42"}}, nil
+	})
+
+	items := zapi.Items{{
+		HostID:     "10084",
+		Key:        "script.status",
+		Name:       "Status from script",
+		Type:       zapi.Script,
+		Parameters: zapi.ItemParameters{{Name: "url", Value: "{$URL}"}},
+	}}
+	if err := api.ItemsCreate(items); err == nil {
+		t.Error("Expected error for script item without script")
+	}
+
+	items[0].Params = "return JSON.parse(value).url;"
+	if err := api.ItemsCreate(items); err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{map[string]interface{}{"name": "url", "value": "{$URL}"}}
+	if len(sent) != 1 || sent[0]["type"] != "21" || !reflect.DeepEqual(sent[0]["parameters"], expected) {
+		t.Errorf("Bad item sent: %#v", sent)
+	}
+}
+
+func TestItemsGetWithLastValue(t *testing.T) {
+	lastValues := true
+	historyCalls := 0
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method == "history.get" {
+			historyCalls++
+			var p map[string]interface{}
+			json.Unmarshal(params, &p)
+			if p["limit"] != 2.0 || p["itemids"] != "1" || p["sortorder"] != "DESC" {
+				t.Errorf("Unbounded history call: %s", params)
+			}
+			return []map[string]string{
+				{"itemid": "1", "clock": "1700000060", "value": "0.5", "ns": "0"},
+				{"itemid": "1", "clock": "1700000000", "value": "0.25", "ns": "0"},
+			}, nil
+		}
+		item := map[string]string{"itemid": "1", "key_": "system.cpu.load", "value_type": "0"}
+		if lastValues {
+			item["lastvalue"], item["lastclock"], item["prevvalue"] = "0.5", "1700000060", "0.25"
+		}
+		return []interface{}{item}, nil
+	})
+
+	for _, lastValues = range []bool{true, false} {
+		items, err := api.ItemsGetWithOptions(zapi.ItemGetOptions{
+			GetOptions:    zapi.GetOptions{Output: []string{"key_"}},
+			ItemIDs:       []string{"1"},
+			WithLastValue: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 || items[0].LastValue != "0.5" || items[0].LastClock != "1700000060" || items[0].PrevValue != "0.25" {
+			t.Errorf("Bad last values (native %v): %#v", lastValues, items)
+		}
+	}
+	if historyCalls != 1 {
+		t.Errorf("Expected one history fallback call, got %d", historyCalls)
+	}
+}
+
+func TestItemsCreateOrdered(t *testing.T) {
+	calls := [][]map[string]interface{}{}
+	nextID := 100
+	api := getMockAPI(t, zapi.Config{Version: 50000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var sent []map[string]interface{}
+		json.Unmarshal(params, &sent)
+		calls = append(calls, sent)
+		ids := []string{}
+		for range sent {
+			nextID++
+			ids = append(ids, fmt.Sprint(nextID))
+		}
+		return map[string][]string{"itemids": ids}, nil
+	})
+
+	items := zapi.Items{
+		{HostID: "1", Key: "vfs.fs.inode.pfree", Type: zapi.Dependent, MasterItemID: "vfs.fs.get"},
+		{HostID: "1", Key: "vfs.fs.pused", Type: zapi.Dependent, MasterItemID: "vfs.fs.get"},
+		{HostID: "1", Key: "vfs.fs.get", Type: zapi.ZabbixAgent},
+	}
+	if err := api.ItemsCreateOrdered(items); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || len(calls[0]) != 1 || calls[0][0]["key_"] != "vfs.fs.get" || len(calls[1]) != 2 {
+		t.Fatalf("Bad create calls: %#v", calls)
+	}
+	for _, dependent := range calls[1] {
+		if dependent["master_itemid"] != "101" {
+			t.Errorf("Master not remapped: %#v", dependent)
+		}
+	}
+	if items[2].ItemID != "101" || items[0].ItemID == "" || items[1].MasterItemID != "101" {
+		t.Errorf("Ids not filled: %#v", items)
+	}
+}
+
+func TestItemsCreateAutoInterface(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{Version: 50000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method == "host.get" {
+			return []interface{}{map[string]interface{}{
+				"hostid": "10084",
+				"host":   "web01",
+				"interfaces": []map[string]string{
+					{"interfaceid": "1", "type": "1", "main": "0"},
+					{"interfaceid": "2", "type": "1", "main": "1"},
+					{"interfaceid": "3", "type": "2", "main": "1"},
+				},
+			}}, nil
+		}
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"itemids": {"100", "101", "102"}}, nil
+	})
+
+	items := zapi.Items{
+		{Key: "agent.ping", Type: zapi.ZabbixAgent},
+		{Key: "sysUpTime", Type: zapi.SNMPAgent, SNMPOid: "1.3.6.1.2.1.1.3.0"},
+		{Key: "trap.in", Type: zapi.ZabbixTrapper},
+	}
+	if err := api.ItemsCreateAutoInterface("10084", items); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 3 || sent[0]["interfaceid"] != "2" || sent[1]["interfaceid"] != "3" || sent[2]["interfaceid"] != nil {
+		t.Errorf("Bad interfaces: %#v", sent)
+	}
+	if sent[0]["hostid"] != "10084" {
+		t.Errorf("Host not set: %#v", sent[0])
+	}
+
+	err := api.ItemsCreateAutoInterface("10084", zapi.Items{{Key: "jmx[java.lang:type=Runtime,Uptime]", Type: zapi.JMXAgent}})
+	if err == nil {
+		t.Error("Expected error for missing JMX interface")
+	}
+}
+
+func TestItemsCreateUnitsValueMap(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{Version: 50000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"itemids": {"42"}}, nil
+	})
+
+	items := zapi.Items{{
+		HostID:     "10084",
+		Key:        "net.if.in[eth0]",
+		Name:       "Incoming traffic",
+		Type:       zapi.ZabbixAgent,
+		ValueType:  zapi.Unsigned,
+		Units:      "bps",
+		ValueMapID: "7",
+	}}
+	if err := api.ItemsCreate(items); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0]["units"] != "bps" || sent[0]["valuemapid"] != "7" {
+		t.Errorf("Bad item sent: %#v", sent)
+	}
+}
+
+func TestItemWithTriggerState(t *testing.T) {
+	var sent map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "item.get":
+			json.Unmarshal(params, &sent)
+			return []map[string]interface{}{{
+				"itemid": "23296", "key_": "system.cpu.load", "value_type": "0",
+				"triggers": []map[string]string{
+					{"triggerid": "13", "description": "High CPU load", "priority": "4", "status": "0", "value": "1", "lastchange": "1700000000"},
+					{"triggerid": "14", "description": "CPU load too low", "priority": "1", "status": "0", "value": "0", "lastchange": "0"},
+				},
+			}}, nil
+		case "trigger.get":
+			return []map[string]string{
+				{"triggerid": "13", "description": "High CPU load", "priority": "4", "status": "0", "value": "1", "lastchange": "1700000000"},
+				{"triggerid": "14", "description": "CPU load too low", "priority": "1", "status": "0", "value": "0", "lastchange": "0"},
+			}, nil
+		case "problem.get":
+			return []map[string]string{{"eventid": "1", "objectid": "13"}}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	item, err := api.ItemWithTriggerState("23296")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sent["selectTriggers"]; !ok {
+		t.Errorf("Triggers not selected: %#v", sent)
+	}
+	if len(item.Triggers) != 2 {
+		t.Fatalf("Expected 2 triggers, got %d", len(item.Triggers))
+	}
+	// Problem is kept for the trigger value
+	if !item.Triggers[0].InProblem() || item.Triggers[0].Value != zapi.Problem || item.Triggers[0].ProblemCount != 1 || item.Triggers[0].Priority != zapi.High {
+		t.Errorf("Bad firing trigger: %#v", item.Triggers[0])
+	}
+	if item.Triggers[1].InProblem() || item.Triggers[1].ProblemCount != 0 {
+		t.Errorf("Bad OK trigger: %#v", item.Triggers[1])
+	}
+}
+
+func TestItemsUpdateStripsInheritedTags(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "item.get":
+			var p map[string]interface{}
+			json.Unmarshal(params, &p)
+			if _, ok := p["hostids"]; ok {
+				return []interface{}{map[string]interface{}{
+					"itemid": "23296", "hostid": "10084", "key_": "system.cpu.load", "templateid": "23001",
+					"type": "0", "value_type": "0",
+					"tags": []interface{}{
+						map[string]string{"tag": "class", "value": "os"},
+						map[string]string{"tag": "component", "value": "cpu"},
+					},
+				}}, nil
+			}
+			if !reflect.DeepEqual(p["itemids"], []interface{}{"23001"}) {
+				t.Errorf("Bad template item lookup: %#v", p)
+			}
+			return []interface{}{map[string]interface{}{
+				"itemid": "23001",
+				"tags":   []interface{}{map[string]string{"tag": "class", "value": "os"}},
+			}}, nil
+		case "item.update":
+			json.Unmarshal(params, &sent)
+			return map[string][]string{"itemids": {"23296"}}, nil
+		}
+		t.Fatalf("Unexpected call %s", method)
+		return nil, nil
+	})
+
+	items, err := api.ItemsGet(zapi.Params{"hostids": "10084", "selectTags": "extend"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := api.ItemsUpdate(items); err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{map[string]interface{}{"tag": "component", "value": "cpu"}}
+	if len(sent) != 1 || !reflect.DeepEqual(sent[0]["tags"], expected) {
+		t.Errorf("Bad tags sent: %#v", sent)
+	}
+	if _, ok := sent[0]["templateid"]; ok {
+		t.Errorf("Read only templateid sent: %#v", sent[0])
+	}
+}
+
+func TestItemValueTypeCache(t *testing.T) {
+	calls := map[string]int{}
+	var history interface{}
+	api := getMockAPI(t, zapi.Config{Version: 50000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		calls[method]++
+		switch method {
+		case "item.get":
+			return []map[string]string{{"itemid": "23296", "key_": "system.cpu.load", "value_type": "0"}}, nil
+		case "item.update":
+			return map[string][]string{"itemids": {"23296"}}, nil
+		case "history.get":
+			var p map[string]interface{}
+			json.Unmarshal(params, &p)
+			history = p["history"]
+			return []map[string]string{{"itemid": "23296", "clock": "1700000000", "value": "0.42", "ns": "0"}}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	if _, err := api.ItemGetByID("23296"); err != nil {
+		t.Fatal(err)
+	}
+	record, err := api.ItemLastValue("23296")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record == nil || record.Value != "0.42" || history != float64(zapi.Float) {
+		t.Errorf("Bad last value %#v for history %v", record, history)
+	}
+	if calls["item.get"] != 1 {
+		t.Errorf("Value type not cached, %d item.get calls", calls["item.get"])
+	}
+
+	if err = api.UpdateItemFields("23296", map[string]interface{}{"value_type": "3"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.ItemValueType("23296"); err != nil {
+		t.Fatal(err)
+	}
+	if calls["item.get"] != 2 {
+		t.Errorf("Value type not invalidated on update, %d item.get calls", calls["item.get"])
+	}
+}
+
+func TestItemsGetPreprocessing(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var p map[string]interface{}
+		json.Unmarshal(params, &p)
+		if p["selectPreprocessing"] != "extend" {
+			t.Errorf("Preprocessing not selected: %s", params)
+		}
+		return []interface{}{map[string]interface{}{
+			"itemid": "1", "key_": "net.if.in[eth0]", "type": "0", "value_type": "3",
+			"preprocessing": []interface{}{
+				map[string]interface{}{"type": "10", "params": "", "error_handler": "0", "error_handler_params": "", "sortorder": "1"},
+				map[string]interface{}{"type": "1", "params": "8", "error_handler": "0", "error_handler_params": "", "sortorder": "0"},
+			},
+		}}, nil
+	})
+
+	items, err := api.ItemsGetWithOptions(zapi.ItemGetOptions{SelectPreprocessing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || len(items[0].Preprocessors) != 2 {
+		t.Fatalf("Bad items: %#v", items)
+	}
+	if steps := items[0].Preprocessors; steps[0].Type != "1" || steps[0].Params != "8" || steps[1].Type != "10" {
+		t.Errorf("Steps out of order: %#v", steps)
+	}
+}
+
+func TestResolveItemDelay(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var p map[string]interface{}
+		json.Unmarshal(params, &p)
+		switch method {
+		case "host.get":
+			return []interface{}{map[string]interface{}{"hostid": "10084", "parentTemplates": []map[string]string{{"templateid": "10001"}}}}, nil
+		case "template.get":
+			return []interface{}{map[string]interface{}{"templateid": "10001", "host": "Template Linux"}}, nil
+		case "usermacro.get":
+			if p["globalmacro"] == true {
+				return []map[string]string{{"macro": "{$POLL}", "value": "5m"}, {"macro": "{$FLEX}", "value": "10s"}}, nil
+			}
+			return []map[string]string{{"hostid": "10001", "macro": "{$POLL}", "value": "2m"}}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	d, err := api.ResolveItemDelay(zapi.Item{Key: "system.cpu.load", Delay: "{$POLL};{$FLEX}/1-5,09:00-18:00;wd1-5h9"}, "10084")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != 2*time.Minute {
+		t.Errorf("Expected template macro value 2m, got %s", d)
+	}
+
+	if _, err = api.ResolveItemDelay(zapi.Item{Key: "k", Delay: "{$MISSING}"}, "10084"); err == nil {
+		t.Error("Expected error for unknown macro")
+	}
+	if _, err = api.ResolveItemDelay(zapi.Item{Key: "k", Delay: "1m;every day"}, "10084"); err == nil {
+		t.Error("Expected error for bad custom interval")
+	}
+}
+
+func TestItemsCreateTimeoutPerVersion(t *testing.T) {
+	for _, c := range []struct {
+		version         int
+		http, agent     string
+		macroTimeoutErr bool
+	}{
+		{60000, "3s", "", true},
+		{70000, "", "5s", false},
+	} {
+		var sent []map[string]interface{}
+		api := getMockAPI(t, zapi.Config{Version: c.version}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+			switch method {
+			case "template.get":
+				return []interface{}{}, nil
+			case "host.get":
+				return []interface{}{map[string]interface{}{"hostid": "10084"}}, nil
+			case "usermacro.get":
+				return []map[string]string{{"hostid": "10084", "macro": "{$HTTP.TIMEOUT}", "value": "90s"}}, nil
+			case "item.create":
+				sent = nil
+				json.Unmarshal(params, &sent)
+				ids := []string{}
+				for range sent {
+					ids = append(ids, fmt.Sprint(100+len(ids)))
+				}
+				return map[string]interface{}{"itemids": ids}, nil
+			}
+			t.Fatalf("Unexpected method %s", method)
+			return nil, nil
+		})
+
+		items := zapi.Items{
+			{HostID: "10084", Key: "web.status", Type: zapi.HTTPAgent, Url: "http://localhost/"},
+			{HostID: "10084", Key: "agent.ping", Type: zapi.ZabbixAgent, Timeout: "5s"},
+			{HostID: "10084", Key: "trap", Type: zapi.ZabbixTrapper, Timeout: "5s"},
+			{HostID: "10084", Key: "log[/var/log/syslog]", Type: zapi.ZabbixAgentActive, Timeout: "5s"},
+		}
+		if err := api.ItemsCreate(items); err != nil {
+			t.Fatal(err)
+		}
+		if len(sent) != 4 {
+			t.Fatalf("Bad items sent on %d: %#v", c.version, sent)
+		}
+		if timeout, _ := sent[0]["timeout"].(string); timeout != c.http {
+			t.Errorf("Expected HTTP agent timeout %q on %d, got %q", c.http, c.version, timeout)
+		}
+		if timeout, _ := sent[1]["timeout"].(string); timeout != c.agent {
+			t.Errorf("Expected agent timeout %q on %d, got %q", c.agent, c.version, timeout)
+		}
+		if _, ok := sent[2]["timeout"]; ok {
+			t.Errorf("Timeout sent for trapper item on %d", c.version)
+		}
+		if timeout, _ := sent[3]["timeout"].(string); timeout != c.agent {
+			t.Errorf("Expected active agent timeout %q on %d, got %q", c.agent, c.version, timeout)
+		}
+
+		sent = nil
+		items = zapi.Items{{HostID: "10084", Key: "web.slow", Type: zapi.HTTPAgent, Url: "http://localhost/", Timeout: "{$HTTP.TIMEOUT}"}}
+		err := api.ItemsCreate(items)
+		if c.macroTimeoutErr && (err == nil || sent != nil) {
+			t.Errorf("Expected error for macro timeout over 60s on %d", c.version)
+		}
+		if !c.macroTimeoutErr && (err != nil || sent[0]["timeout"] != "{$HTTP.TIMEOUT}") {
+			t.Errorf("Macro timeout not sent on %d: %v %#v", c.version, err, sent)
+		}
+
+		d, err := api.ResolveItemTimeout(zapi.Item{Key: "web.status", Type: zapi.HTTPAgent}, "10084")
+		if want := map[int]time.Duration{60000: 3 * time.Second, 70000: 0}[c.version]; err != nil || d != want {
+			t.Errorf("Expected default timeout %s on %d, got %s %v", want, c.version, d, err)
+		}
+	}
+}
+
+func TestValidateCalculatedFormula(t *testing.T) {
+	in := zapi.CalcFunc("last", "", "net.if.in[eth0]")
+	out := zapi.CalcFunc("avg", "Linux server", "net.if.out[eth0]", "5m")
+	formula := in.With("+", out).Group().With("*", "8")
+	if formula != `(last(//net.if.in[eth0]) + avg(/Linux server/net.if.out[eth0],5m)) * 8` {
+		t.Errorf("Bad formula built: %s", formula)
+	}
+
+	for _, valid := range []string{
+		string(formula),
+		`100*last(//vfs.fs.size[/,free])/last(//vfs.fs.size[/,total])`,
+		`sum(last_foreach(/*/net.if.in[*]?[group="Linux servers" and tag="iface:eth0"]))`,
+		`count(//system.run["echo \"a,b)\""],1h,"regexp","^ok$")`,
+		`last(/{HOST.HOST}/vfs.fs.size[{#FSNAME},pfree])`,
+	} {
+		if err := zapi.ValidateCalculatedFormula(valid); err != nil {
+			t.Errorf("%s: %s", valid, err)
+		}
+	}
+	for _, invalid := range []string{
+		``,
+		`last(//agent.ping`,
+		`last(//agent.ping))`,
+		`last(/agent.ping)`,
+		`last(/host/)`,
+		`last(//vfs.fs.size[/,free)`,
+		`last(//agent.ping 5m)`,
+		`count(//log,"error)`,
+	} {
+		if err := zapi.ValidateCalculatedFormula(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+	items := zapi.Items{{HostID: "10084", Key: "net.total", Type: zapi.Calculated, Params: "last(/agent.ping)"}}
+	if err := api.ItemsCreate(items); err == nil {
+		t.Error("Expected error for bad calculated formula")
+	}
+}
+
+func TestItemMasterChain(t *testing.T) {
+	items := map[string]map[string]string{
+		"100": {"itemid": "100", "key_": "http.raw", "type": "19", "master_itemid": "0"},
+		"101": {"itemid": "101", "key_": "http.json", "type": "18", "master_itemid": "100"},
+		"102": {"itemid": "102", "key_": "http.json.status", "type": "18", "master_itemid": "101"},
+		"201": {"itemid": "201", "key_": "a", "type": "18", "master_itemid": "202"},
+		"202": {"itemid": "202", "key_": "b", "type": "18", "master_itemid": "201"},
+	}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var p struct {
+			ItemIDs string `json:"itemids"`
+		}
+		json.Unmarshal(params, &p)
+		if item, ok := items[p.ItemIDs]; ok {
+			return []map[string]string{item}, nil
+		}
+		return []map[string]string{}, nil
+	})
+
+	chain, err := api.ItemMasterChain("102")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{}
+	for _, item := range chain {
+		keys = append(keys, item.Key)
+	}
+	if !reflect.DeepEqual(keys, []string{"http.raw", "http.json", "http.json.status"}) {
+		t.Errorf("Bad chain: %v", keys)
+	}
+
+	if chain, err = api.ItemMasterChain("100"); err != nil || len(chain) != 1 {
+		t.Errorf("Bad chain of a master item: %#v %v", chain, err)
+	}
+	if _, err = api.ItemMasterChain("201"); err == nil {
+		t.Error("Expected error for a dependency loop")
+	}
+	if _, err = api.ItemMasterChain("999"); err == nil {
+		t.Error("Expected error for a missing item")
+	}
+}
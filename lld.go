@@ -43,6 +43,7 @@ type LLDMacroPaths []LLDMacroPath
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/object
 type LLDRule struct {
 	ItemID      string   `json:"itemid,omitempty"`
+	UUID        string   `json:"uuid,omitempty"`
 	Delay       string   `json:"delay"`
 	HostID      string   `json:"hostid"`
 	InterfaceID string   `json:"interfaceid,omitempty"`
@@ -169,6 +170,9 @@ func (api *API) LLDGetByID(id string) (res *LLDRule, err error) {
 // ItemsCreate Wrapper for item.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/create
 func (api *API) LLDsCreate(items LLDRules) (err error) {
+	if err = api.fillLLDUUIDs(items); err != nil {
+		return
+	}
 	prepLLDs(items)
 	response, err := api.CallWithError("discoveryrule.create", items)
 	if err != nil {
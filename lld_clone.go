@@ -0,0 +1,455 @@
+package zabbix
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CloneDiscoveryRule Copies the discovery rule with its item, trigger, graph and host prototypes
+// to another host or template, the copy of the rule having the key newKey.
+// Dependent prototypes are linked to the copies of their masters, items outside of the rule
+// referenced by prototypes must exist on the destination with the same key.
+// Item references of trigger expressions are moved to the destination host. Dependencies
+// between cloned trigger prototypes follow them, those on triggers of the source host go to
+// the destination trigger with the same name and expression, which must exist.
+// The copied rule is deleted, with its prototypes, if a step fails.
+func (api *API) CloneDiscoveryRule(srcRuleID, destHostID, newKey string) (res *LLDRule, err error) {
+	rules, err := api.LLDsGet(Params{
+		"itemids":             srcRuleID,
+		"selectFilter":        "extend",
+		"selectLLDMacroPaths": "extend",
+		"selectPreprocessing": "extend",
+	})
+	if err != nil {
+		return
+	}
+	if len(rules) != 1 {
+		e := ExpectedOneResult(len(rules))
+		return nil, &e
+	}
+	src := rules[0]
+	if src.HostID == destHostID {
+		return nil, fmt.Errorf("Discovery rule %s: prototypes can not be cloned on the same host", src.Key)
+	}
+
+	hosts, err := api.HostsGet(Params{
+		"output":           []string{"hostid", "host"},
+		"hostids":          []string{src.HostID, destHostID},
+		"templated_hosts":  true,
+		"selectInterfaces": "extend",
+	})
+	if err != nil {
+		return
+	}
+	var srcHost, destHost *Host
+	for i := range hosts {
+		switch hosts[i].HostID {
+		case src.HostID:
+			srcHost = &hosts[i]
+		case destHostID:
+			destHost = &hosts[i]
+		}
+	}
+	if srcHost == nil {
+		return nil, &NotFoundError{"host", []string{src.HostID}}
+	}
+	if destHost == nil {
+		return nil, &NotFoundError{"host", []string{destHostID}}
+	}
+
+	items, err := api.ProtoItemsGet(Params{"discoveryids": srcRuleID, "selectPreprocessing": "extend", "selectTags": "extend"})
+	if err != nil {
+		return
+	}
+	triggers, err := api.ProtoTriggersGet(Params{
+		"discoveryids":       srcRuleID,
+		"expandExpression":   true,
+		"selectDependencies": []string{"triggerid"},
+		"selectTags":         "extend",
+	})
+	if err != nil {
+		return
+	}
+	graphs, err := api.GraphProtosGet(Params{"discoveryids": srcRuleID, "selectGraphItems": "extend"})
+	if err != nil {
+		return
+	}
+	hostPrototypes, err := api.sourceHostPrototypes(srcRuleID)
+	if err != nil {
+		return
+	}
+
+	// ids of the source items referenced from outside of the prototypes
+	prototypes := map[string]bool{}
+	for _, item := range items {
+		prototypes[item.ItemID] = true
+	}
+	external := []string{}
+	for _, id := range append([]string{src.MasterItemID}, referencedItemIDs(items, graphs)...) {
+		if id != "" && id != "0" && !prototypes[id] {
+			external = append(external, id)
+		}
+	}
+	ids, err := api.destItemIDs(external, destHostID)
+	if err != nil {
+		return
+	}
+
+	main := newMainInterfaces(destHost.Interfaces)
+	rule := src
+	rule.ItemID, rule.UUID, rule.Error = "", "", ""
+	rule.HostID, rule.Key = destHostID, newKey
+	rule.InterfaceID, _ = main.forType(rule.Type)
+	rule.MasterItemID = ids[src.MasterItemID]
+	for i := range rule.Preprocessors {
+		rule.Preprocessors[i].SortOrder = ""
+	}
+	created := LLDRules{rule}
+	if err = api.LLDsCreate(created); err != nil {
+		return
+	}
+	res = &created[0]
+	defer func() {
+		if err != nil {
+			api.printf("Deleting discovery rule %s after failed clone: %s", res.ItemID, err)
+			api.LLDDeleteByIds([]string{res.ItemID})
+			res = nil
+		}
+	}()
+
+	if err = api.cloneItemPrototypes(items, res.ItemID, destHostID, main, ids); err != nil {
+		return
+	}
+	if err = api.cloneTriggerPrototypes(triggers, srcHost, destHost); err != nil {
+		return
+	}
+	if err = api.cloneGraphPrototypes(graphs, ids); err != nil {
+		return
+	}
+	err = api.cloneHostPrototypes(hostPrototypes, res.ItemID)
+	return
+}
+
+// referencedItemIDs returns the ids of the master items of the items and of the graphs items
+func referencedItemIDs(items Items, graphs Graphs) (res []string) {
+	for _, item := range items {
+		res = append(res, item.MasterItemID)
+	}
+	for _, graph := range graphs {
+		res = append(res, graph.YMinItemId, graph.YMaxItemId)
+		for _, gitem := range graph.GraphItems {
+			res = append(res, gitem.ItemID)
+		}
+	}
+	return
+}
+
+// destItemIDs maps the ids of source items to the ids of the items having the same key on destHostID
+func (api *API) destItemIDs(srcIDs []string, destHostID string) (res map[string]string, err error) {
+	res = map[string]string{}
+	if len(srcIDs) == 0 {
+		return
+	}
+	src, err := api.ItemsGet(Params{"output": []string{"itemid", "key_"}, "itemids": srcIDs})
+	if err != nil {
+		return
+	}
+	keys := make([]string, len(src))
+	for i, item := range src {
+		keys[i] = item.Key
+	}
+	dest, err := api.ItemsGet(Params{"output": []string{"itemid", "key_"}, "hostids": destHostID, "filter": map[string]interface{}{"key_": keys}})
+	if err != nil {
+		return
+	}
+	byKey := map[string]string{}
+	for _, item := range dest {
+		byKey[item.Key] = item.ItemID
+	}
+	if err = checkResolved("item", keys, byKey); err != nil {
+		return
+	}
+	for _, item := range src {
+		res[item.ItemID] = byKey[item.Key]
+	}
+	return
+}
+
+// cloneItemPrototypes creates copies of the item prototypes in the rule, masters before
+// their dependent items. ids maps source to copied item ids and is completed.
+func (api *API) cloneItemPrototypes(items Items, ruleID, hostID string, main mainInterfaces, ids map[string]string) error {
+	pending := map[string]bool{}
+	for _, item := range items {
+		pending[item.ItemID] = true
+	}
+	for len(pending) > 0 {
+		level, srcIDs := Items{}, []string{}
+		for _, item := range items {
+			if !pending[item.ItemID] || pending[item.MasterItemID] {
+				continue
+			}
+			srcIDs = append(srcIDs, item.ItemID)
+			item.ItemID, item.UUID, item.Error, item.State = "", "", "", ItemStateNormal
+			item.HostID, item.RuleID, item.DiscoveryRule = hostID, ruleID, nil
+			item.InterfaceID, _ = main.forType(item.Type)
+			if item.MasterItemID != "" {
+				item.MasterItemID = ids[item.MasterItemID]
+			}
+			level = append(level, item)
+		}
+		if len(level) == 0 {
+			return fmt.Errorf("Item prototypes of rule %s depend on each other in a loop", ruleID)
+		}
+		if err := api.ProtoItemsCreate(level); err != nil {
+			return err
+		}
+		for i, id := range srcIDs {
+			ids[id] = level[i].ItemID
+			delete(pending, id)
+		}
+	}
+	return nil
+}
+
+// moveExpressionHost rewrites the item references to srcHost in expression to destHost,
+// both /host/key and the {host:key.func()} references of Zabbix before 5.4
+func moveExpressionHost(expression, srcHost, destHost string) string {
+	var b strings.Builder
+	reference := false
+	for i := 0; i < len(expression); i++ {
+		c := expression[i]
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			b.WriteByte(c)
+			continue
+		case '"':
+			n := quotedLength(expression[i:])
+			if n < 0 {
+				b.WriteString(expression[i:])
+				return b.String()
+			}
+			b.WriteString(expression[i : i+n])
+			i += n - 1
+			reference = false
+			continue
+		case '(', ',':
+			b.WriteByte(c)
+			reference = true
+			continue
+		case '/':
+			if n, err := itemReferenceLength(expression[i:]); reference && err == nil {
+				ref := expression[i : i+n]
+				if strings.HasPrefix(ref, "/"+srcHost+"/") {
+					ref = "/" + destHost + ref[1+len(srcHost):]
+				}
+				b.WriteString(ref)
+				i += n - 1
+				reference = false
+				continue
+			}
+		case '{':
+			if strings.HasPrefix(expression[i+1:], srcHost+":") {
+				b.WriteString("{" + destHost + ":")
+				i += len(srcHost) + 1
+				reference = false
+				continue
+			}
+		}
+		b.WriteByte(c)
+		reference = false
+	}
+	return b.String()
+}
+
+// destTriggerIDs maps the ids of source triggers to the ids of the triggers of destHost having
+// the same name and expression, triggers not on srcHost being mapped to themselves
+func (api *API) destTriggerIDs(srcIDs []string, srcHost, destHost *Host) (res map[string]string, err error) {
+	res = map[string]string{}
+	if len(srcIDs) == 0 {
+		return
+	}
+	src, err := api.TriggersGet(Params{
+		"output":           []string{"triggerid", "description", "expression"},
+		"triggerids":       srcIDs,
+		"expandExpression": true,
+		"selectHosts":      []string{"hostid"},
+	})
+	if err != nil {
+		return
+	}
+	moved := Triggers{}
+	descriptions := []string{}
+	for _, trigger := range src {
+		onSource := false
+		for _, h := range trigger.ParentHosts {
+			onSource = onSource || h.HostID == srcHost.HostID
+		}
+		if !onSource {
+			res[trigger.TriggerID] = trigger.TriggerID
+			continue
+		}
+		trigger.Expression = moveExpressionHost(trigger.Expression, srcHost.Host, destHost.Host)
+		moved = append(moved, trigger)
+		descriptions = append(descriptions, trigger.Description)
+	}
+	if len(moved) == 0 {
+		return
+	}
+
+	dest, err := api.TriggersGet(Params{
+		"output":           []string{"triggerid", "description", "expression"},
+		"hostids":          destHost.HostID,
+		"filter":           map[string]interface{}{"description": descriptions},
+		"expandExpression": true,
+	})
+	if err != nil {
+		return
+	}
+	byExpression := map[string]string{}
+	for _, trigger := range dest {
+		byExpression[trigger.Description+"\n"+trigger.Expression] = trigger.TriggerID
+	}
+	found := map[string]string{}
+	for _, trigger := range moved {
+		if id, ok := byExpression[trigger.Description+"\n"+trigger.Expression]; ok {
+			res[trigger.TriggerID], found[trigger.Description] = id, id
+		}
+	}
+	err = checkResolved("trigger", descriptions, found)
+	return
+}
+
+// cloneTriggerPrototypes creates copies of the trigger prototypes with their expressions moved
+// from srcHost to destHost, then sets the dependencies of the copies
+func (api *API) cloneTriggerPrototypes(triggers Triggers, srcHost, destHost *Host) (err error) {
+	if len(triggers) == 0 {
+		return
+	}
+	clones := make(Triggers, len(triggers))
+	for i, trigger := range triggers {
+		trigger.TriggerID, trigger.UUID = "", ""
+		trigger.Expression = moveExpressionHost(trigger.Expression, srcHost.Host, destHost.Host)
+		trigger.RecoveryExpression = moveExpressionHost(trigger.RecoveryExpression, srcHost.Host, destHost.Host)
+		trigger.Dependencies, trigger.Functions, trigger.ContainedItems, trigger.ParentHosts = nil, nil, nil, nil
+		clones[i] = trigger
+	}
+	if err = api.ProtoTriggersCreate(clones); err != nil {
+		return
+	}
+
+	cloned := map[string]string{}
+	for i, trigger := range triggers {
+		cloned[trigger.TriggerID] = clones[i].TriggerID
+	}
+	external := []string{}
+	for _, trigger := range triggers {
+		for _, dep := range trigger.Dependencies {
+			if _, ok := cloned[dep.TriggerID]; !ok {
+				external = append(external, dep.TriggerID)
+			}
+		}
+	}
+	ids, err := api.destTriggerIDs(external, srcHost, destHost)
+	if err != nil {
+		return
+	}
+	for src, id := range cloned {
+		ids[src] = id
+	}
+
+	updates := []Params{}
+	for i, trigger := range triggers {
+		if len(trigger.Dependencies) == 0 {
+			continue
+		}
+		deps := TriggerIDs{}
+		for _, dep := range trigger.Dependencies {
+			id, ok := ids[dep.TriggerID]
+			if !ok {
+				return &NotFoundError{"trigger", []string{dep.TriggerID}}
+			}
+			deps = append(deps, TriggerID{id})
+		}
+		updates = append(updates, Params{"triggerid": clones[i].TriggerID, "dependencies": deps})
+	}
+	if len(updates) > 0 {
+		_, err = api.CallWithError("triggerprototype.update", updates)
+	}
+	return
+}
+
+// cloneGraphPrototypes creates copies of the graph prototypes using the items mapped by ids
+func (api *API) cloneGraphPrototypes(graphs Graphs, ids map[string]string) error {
+	if len(graphs) == 0 {
+		return nil
+	}
+	clones := make(Graphs, len(graphs))
+	for i, graph := range graphs {
+		graph.GraphID, graph.UUID, graph.ParentHosts = "", "", nil
+		if graph.YMinItemId != "" && graph.YMinItemId != "0" {
+			graph.YMinItemId = ids[graph.YMinItemId]
+		}
+		if graph.YMaxItemId != "" && graph.YMaxItemId != "0" {
+			graph.YMaxItemId = ids[graph.YMaxItemId]
+		}
+		gitems := make(GraphItems, len(graph.GraphItems))
+		for j, gitem := range graph.GraphItems {
+			gitem.GItemID, gitem.GraphID = "", ""
+			gitem.ItemID = ids[gitem.ItemID]
+			gitems[j] = gitem
+		}
+		graph.GraphItems = gitems
+		clones[i] = graph
+	}
+	return api.GraphProtosCreate(clones)
+}
+
+// sourceHostPrototypes returns the host prototypes of the rule with what is needed to copy them
+func (api *API) sourceHostPrototypes(ruleID string) (res HostPrototypes, err error) {
+	v, err := api.ServerVersion()
+	if err != nil {
+		return
+	}
+	params := Params{
+		"discoveryids":          ruleID,
+		"selectGroupLinks":      []string{"groupid"},
+		"selectGroupPrototypes": []string{"name"},
+		"selectTemplates":       []string{"templateid"},
+	}
+	if v >= 50000 {
+		params["selectMacros"] = "extend"
+	}
+	if v >= 50200 {
+		params["selectTags"] = "extend"
+		params["selectInterfaces"] = "extend"
+	}
+	return api.HostPrototypesGet(params)
+}
+
+// cloneHostPrototypes creates copies of the host prototypes in the rule ruleID
+func (api *API) cloneHostPrototypes(prototypes HostPrototypes, ruleID string) error {
+	if len(prototypes) == 0 {
+		return nil
+	}
+	clones := make(HostPrototypes, len(prototypes))
+	for i, prototype := range prototypes {
+		prototype.HostID, prototype.RuleID = "", ruleID
+		macros := make(Macros, len(prototype.UserMacros))
+		for j, macro := range prototype.UserMacros {
+			macro.MacroID, macro.HostID = "", ""
+			macros[j] = macro
+		}
+		prototype.UserMacros = macros
+		interfaces := make(HostInterfaces, len(prototype.Interfaces))
+		for j, iface := range prototype.Interfaces {
+			iface.InterfaceID, iface.HostID = "", ""
+			if string(iface.RawDetails) == "[]" {
+				iface.RawDetails = nil
+			}
+			interfaces[j] = iface
+		}
+		prototype.Interfaces = interfaces
+		clones[i] = prototype
+	}
+	return api.HostPrototypesCreate(clones)
+}
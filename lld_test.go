@@ -0,0 +1,115 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestCloneDiscoveryRule(t *testing.T) {
+	sent := map[string][]map[string]interface{}{}
+	api := getMockAPI(t, zapi.Config{Version: 50000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "discoveryrule.get":
+			return []interface{}{map[string]interface{}{
+				"itemid": "100", "hostid": "10001", "key_": "vfs.fs.discovery", "name": "Filesystems", "type": "0",
+				"delay": "1h", "interfaceid": "1", "filter": map[string]interface{}{"evaltype": "0", "formula": "", "conditions": []interface{}{}},
+			}}, nil
+		case "host.get":
+			return []interface{}{
+				map[string]interface{}{"hostid": "10001", "host": "Template Linux"},
+				map[string]interface{}{"hostid": "10084", "host": "web01", "interfaces": []map[string]string{
+					{"interfaceid": "7", "type": "1", "main": "1"},
+				}},
+			}, nil
+		case "itemprototype.get":
+			return []interface{}{map[string]interface{}{
+				"itemid": "101", "hostid": "10001", "key_": "vfs.fs.size[{#FSNAME},pfree]", "name": "Free on {#FSNAME}",
+				"type": "0", "value_type": "0", "delay": "5m", "interfaceid": "1",
+			}}, nil
+		case "triggerprototype.get":
+			return []interface{}{
+				map[string]interface{}{
+					"triggerid": "102", "description": "Low space on {#FSNAME}", "priority": "2",
+					"expression":   "last(/Template Linux/vfs.fs.size[{#FSNAME},pfree])<10 and last(/Template Linux/log[/Template Linux/x])=0",
+					"dependencies": []map[string]string{{"triggerid": "103"}, {"triggerid": "300"}},
+				},
+				map[string]interface{}{
+					"triggerid": "103", "description": "Very low space on {#FSNAME}", "priority": "4",
+					"expression": "last(/Template Linux/vfs.fs.size[{#FSNAME},pfree])<5",
+				},
+			}, nil
+		case "trigger.get":
+			var p map[string]interface{}
+			json.Unmarshal(params, &p)
+			if p["hostids"] == "10084" {
+				return []interface{}{map[string]interface{}{
+					"triggerid": "400", "description": "Agent down", "expression": "nodata(/web01/agent.ping,5m)=1",
+				}}, nil
+			}
+			return []interface{}{map[string]interface{}{
+				"triggerid": "300", "description": "Agent down", "expression": "nodata(/Template Linux/agent.ping,5m)=1",
+				"hosts": []map[string]string{{"hostid": "10001"}},
+			}}, nil
+		case "graphprototype.get":
+			return []interface{}{}, nil
+		case "hostprototype.get":
+			return []interface{}{map[string]interface{}{
+				"hostid": "104", "host": "{#VM.NAME}", "name": "VM {#VM.NAME}", "status": "0",
+				"groupLinks":      []map[string]string{{"groupid": "2"}},
+				"groupPrototypes": []map[string]string{{"name": "VMs {#CLUSTER}"}},
+				"templates":       []map[string]string{{"templateid": "10050"}},
+			}}, nil
+		case "triggerprototype.update":
+			var objs []map[string]interface{}
+			json.Unmarshal(params, &objs)
+			sent[method] = objs
+			return map[string][]string{"triggerids": {"202"}}, nil
+		case "discoveryrule.create", "itemprototype.create", "triggerprototype.create", "hostprototype.create":
+			var objs []map[string]interface{}
+			json.Unmarshal(params, &objs)
+			sent[method] = objs
+			ids := map[string]map[string][]string{
+				"discoveryrule.create":    {"itemids": {"200"}},
+				"itemprototype.create":    {"itemids": {"201"}},
+				"triggerprototype.create": {"triggerids": {"202", "203"}},
+				"hostprototype.create":    {"hostids": {"204"}},
+			}
+			return ids[method], nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	rule, err := api.CloneDiscoveryRule("100", "10084", "vfs.fs.discovery[copy]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.ItemID != "200" || rule.HostID != "10084" || rule.Key != "vfs.fs.discovery[copy]" {
+		t.Errorf("Bad rule: %#v", rule)
+	}
+	if r := sent["discoveryrule.create"]; len(r) != 1 || r[0]["interfaceid"] != "7" || r[0]["itemid"] != nil {
+		t.Errorf("Bad rule sent: %#v", r)
+	}
+	if items := sent["itemprototype.create"]; len(items) != 1 || items[0]["ruleid"] != "200" || items[0]["hostid"] != "10084" ||
+		items[0]["interfaceid"] != "7" || items[0]["key_"] != "vfs.fs.size[{#FSNAME},pfree]" || items[0]["itemid"] != nil {
+		t.Errorf("Bad item prototypes sent: %#v", items)
+	}
+	if triggers := sent["triggerprototype.create"]; len(triggers) != 2 ||
+		triggers[0]["expression"] != "last(/web01/vfs.fs.size[{#FSNAME},pfree])<10 and last(/web01/log[/Template Linux/x])=0" ||
+		triggers[0]["triggerid"] != nil || triggers[0]["dependencies"] != nil {
+		t.Errorf("Bad trigger prototypes sent: %#v", triggers)
+	}
+	deps := []interface{}{map[string]interface{}{"triggerid": "203"}, map[string]interface{}{"triggerid": "400"}}
+	if updates := sent["triggerprototype.update"]; len(updates) != 1 || updates[0]["triggerid"] != "202" ||
+		!reflect.DeepEqual(updates[0]["dependencies"], deps) {
+		t.Errorf("Bad dependencies sent: %#v", updates)
+	}
+	groupLinks := []interface{}{map[string]interface{}{"groupid": "2"}}
+	if hosts := sent["hostprototype.create"]; len(hosts) != 1 || hosts[0]["ruleid"] != "200" || hosts[0]["hostid"] != nil ||
+		hosts[0]["host"] != "{#VM.NAME}" || !reflect.DeepEqual(hosts[0]["groupLinks"], groupLinks) {
+		t.Errorf("Bad host prototypes sent: %#v", hosts)
+	}
+}
@@ -1,12 +1,87 @@
 package zabbix
 
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MacroType type of the macro value, Zabbix 5.0+
+// see "type" in https://www.zabbix.com/documentation/6.0/manual/api/reference/usermacro/object
+type MacroType int
+
+const (
+	// MacroTypeText plain text value
+	MacroTypeText MacroType = 0
+	// MacroTypeSecret value hidden once set
+	MacroTypeSecret MacroType = 1
+	// MacroTypeVault value is the path of the secret in a Vault, path:key
+	MacroTypeVault MacroType = 2
+)
+
 // Macro represent Zabbix User MAcro object
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/usermacro/object
 type Macro struct {
-	MacroID   string `json:"hostmacroids,omitempty"`
+	MacroID   string `json:"hostmacroid,omitempty"`
 	HostID    string `json:"hostid,omitempty"`
 	MacroName string `json:"macro"`
 	Value     string `json:"value"`
+	// Type is always sent from Zabbix 5.0, the value of secret macros is not returned
+	Type MacroType `json:"type,string"`
+
+	// untyped leaves Type out, for servers before Zabbix 5.0
+	untyped bool
+}
+
+// MarshalJSON encodes the macro, without its type for servers before Zabbix 5.0
+func (m Macro) MarshalJSON() ([]byte, error) {
+	type macro Macro
+	if !m.untyped {
+		return json.Marshal(macro(m))
+	}
+	return json.Marshal(struct {
+		macro
+		Type *MacroType `json:"type,omitempty"`
+	}{macro: macro(m)})
+}
+
+// prepMacroTypes leaves the type out of the macros on servers before Zabbix 5.0,
+// macros are left as they are when the version is unknown
+func (api *API) prepMacroTypes(macros Macros) {
+	if len(macros) == 0 {
+		return
+	}
+	untyped := api.untypedMacros()
+	for i := range macros {
+		macros[i].untyped = untyped
+	}
+}
+
+// untypedMacros reports whether the server predates macro types, false when the version is unknown
+func (api *API) untypedMacros() bool {
+	v, err := api.ServerVersion()
+	return err == nil && v < 50000
+}
+
+// validateMacroValue checks that Vault macros have the path of their secret
+func validateMacroValue(name, value string, t MacroType) error {
+	if t == MacroTypeVault && strings.TrimSpace(value) == "" {
+		return fmt.Errorf("Macro %s: Vault macros require the secret path as value", name)
+	}
+	return nil
+}
+
+// validateMacros checks the macros before they are sent
+func validateMacros(macros Macros) error {
+	for _, m := range macros {
+		if err := validateMacroValue(m.MacroName, m.Value, m.Type); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Macros is an array of Macro
@@ -41,6 +116,10 @@ func (api *API) MacroGetByID(id string) (res *Macro, err error) {
 // MacrosCreate Wrapper for usermacro.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/usermacro/create
 func (api *API) MacrosCreate(macros Macros) error {
+	if err := validateMacros(macros); err != nil {
+		return err
+	}
+	api.prepMacroTypes(macros)
 	response, err := api.CallWithError("usermacro.create", macros)
 	if err != nil {
 		return err
@@ -57,7 +136,17 @@ func (api *API) MacrosCreate(macros Macros) error {
 // MacrosUpdate Wrapper for usermacro.update
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/usermacro/update
 func (api *API) MacrosUpdate(macros Macros) (err error) {
-	_, err = api.CallWithError("usermacro.create", macros)
+	if err = validateMacros(macros); err != nil {
+		return
+	}
+	api.prepMacroTypes(macros)
+	// the host of a macro can not be changed
+	updates := make(Macros, len(macros))
+	for i, m := range macros {
+		m.HostID = ""
+		updates[i] = m
+	}
+	_, err = api.CallWithError("usermacro.update", updates)
 	return
 }
 
@@ -91,3 +180,77 @@ func (api *API) MacrosDelete(macros Macros) (err error) {
 	}
 	return
 }
+
+// userMacroRegexp matches user macros, with an optional context
+var userMacroRegexp = regexp.MustCompile(`\{\$[A-Z0-9_.]+(?::[^}]*)?\}`)
+
+// EffectiveMacros Gets the user macros applying to the host by name: its own macros,
+// then those of its templates, nearest first, then the global macros.
+// Templates linked at the same distance are taken by ascending id, as the server does.
+func (api *API) EffectiveMacros(hostID string) (res map[string]string, err error) {
+	templates, err := api.HostTemplateTree(hostID)
+	if err != nil {
+		return
+	}
+	sort.SliceStable(templates, func(i, j int) bool {
+		if templates[i].Depth != templates[j].Depth {
+			return templates[i].Depth < templates[j].Depth
+		}
+		a, _ := strconv.Atoi(templates[i].TemplateID)
+		b, _ := strconv.Atoi(templates[j].TemplateID)
+		return a < b
+	})
+	order := []string{hostID}
+	for _, t := range templates {
+		order = append(order, t.TemplateID)
+	}
+
+	macros, err := api.MacrosGet(Params{"output": []string{"hostid", "macro", "value"}, "hostids": order})
+	if err != nil {
+		return
+	}
+	global, err := api.MacrosGet(Params{"output": []string{"macro", "value"}, "globalmacro": true})
+	if err != nil {
+		return
+	}
+
+	byHost := map[string]Macros{}
+	for _, m := range macros {
+		byHost[m.HostID] = append(byHost[m.HostID], m)
+	}
+	res = map[string]string{}
+	for _, id := range append(order, "") {
+		list := byHost[id]
+		if id == "" {
+			list = global
+		}
+		for _, m := range list {
+			if _, ok := res[m.MacroName]; !ok {
+				res[m.MacroName] = m.Value
+			}
+		}
+	}
+	return
+}
+
+// expandUserMacros replaces the user macros in s by their value in macros, a macro with
+// a context missing there takes the value of the macro without context
+func expandUserMacros(s string, macros map[string]string) (string, error) {
+	var missing []string
+	res := userMacroRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		if v, ok := macros[m]; ok {
+			return v
+		}
+		if i := strings.Index(m, ":"); i >= 0 {
+			if v, ok := macros[m[:i]+"}"]; ok {
+				return v
+			}
+		}
+		missing = append(missing, m)
+		return m
+	})
+	if len(missing) > 0 {
+		return res, &NotFoundError{"macro", missing}
+	}
+	return res, nil
+}
@@ -0,0 +1,118 @@
+package zabbix
+
+import "errors"
+
+const (
+	// SortAsc ascending sort order
+	SortAsc = "ASC"
+	// SortDesc descending sort order
+	SortDesc = "DESC"
+)
+
+// GetOptions holds the parameters common to all get methods
+// https://www.zabbix.com/documentation/3.2/manual/api/reference_commentary#common_get_method_parameters
+type GetOptions struct {
+	// Output defaults to "extend" when nil
+	Output    interface{}
+	Filter    map[string]interface{}
+	Search    map[string]interface{}
+	SortField string
+	// SortFields sorts on several fields, after SortField if set
+	SortFields []string
+	SortOrder  string
+	Limit      int
+	// ErrorIfTruncated makes typed getters return ErrResultsTruncated, along with
+	// the results, when they get Limit results
+	ErrorIfTruncated bool
+
+	// Search modifiers
+	StartSearch            bool
+	ExcludeSearch          bool
+	SearchByAny            bool
+	SearchWildcardsEnabled bool
+}
+
+func (o GetOptions) params() Params {
+	params := Params{}
+	if o.Output != nil {
+		params["output"] = o.Output
+	}
+	if len(o.Filter) > 0 {
+		filter := make(map[string]interface{}, len(o.Filter))
+		for k, v := range o.Filter {
+			filter[k] = v
+		}
+		params["filter"] = filter
+	}
+	if len(o.Search) > 0 {
+		params["search"] = o.Search
+	}
+	if len(o.SortFields) > 0 {
+		fields := o.SortFields
+		if o.SortField != "" {
+			fields = append([]string{o.SortField}, fields...)
+		}
+		params["sortfield"] = fields
+	} else if o.SortField != "" {
+		params["sortfield"] = o.SortField
+	}
+	if o.SortOrder != "" {
+		params["sortorder"] = o.SortOrder
+	}
+	if o.Limit > 0 {
+		params["limit"] = o.Limit
+	}
+	for key, set := range map[string]bool{
+		"startSearch":            o.StartSearch,
+		"excludeSearch":          o.ExcludeSearch,
+		"searchByAny":            o.SearchByAny,
+		"searchWildcardsEnabled": o.SearchWildcardsEnabled,
+	} {
+		if set {
+			params[key] = true
+		}
+	}
+	return params
+}
+
+// withDefaultSort returns the options sorted on fields in descending order
+// when no sort field is set, newest first for time ordered objects
+func (o GetOptions) withDefaultSort(fields ...string) GetOptions {
+	if o.SortField == "" && len(o.SortFields) == 0 {
+		o.SortFields = fields
+		if o.SortOrder == "" {
+			o.SortOrder = SortDesc
+		}
+	}
+	return o
+}
+
+// ErrResultsTruncated is returned with results of typed getters reaching the limit when
+// GetOptions.ErrorIfTruncated is set. Results may be complete when exactly at the limit,
+// only comparing with a count (countOutput) tells for sure.
+var ErrResultsTruncated = errors.New("zabbix: results may be truncated at the limit")
+
+// truncated returns ErrResultsTruncated when asked for and n reaches the limit
+func (o GetOptions) truncated(n int) error {
+	if o.ErrorIfTruncated && o.Limit > 0 && n >= o.Limit {
+		return ErrResultsTruncated
+	}
+	return nil
+}
+
+// setIDs sets key to ids if there are any
+func (params Params) setIDs(key string, ids []string) {
+	if len(ids) > 0 {
+		params[key] = ids
+	}
+}
+
+// setFilter adds key to the filter, creating it if needed
+func (params Params) setFilter(key string, value interface{}) {
+	filter, ok := params["filter"].(map[string]interface{})
+	if !ok {
+		filter = map[string]interface{}{}
+		params["filter"] = filter
+	}
+	filter[key] = value
+}
@@ -0,0 +1,87 @@
+package zabbix
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// Preprocessing step types used as Preprocessor.Type
+	// see "type" in https://www.zabbix.com/documentation/6.4/manual/api/reference/item/object#item-preprocessing
+
+	// PreprocJavaScript JavaScript
+	PreprocJavaScript = "21"
+	// PreprocPrometheusPattern Prometheus pattern
+	PreprocPrometheusPattern = "22"
+	// PreprocPrometheusToJSON Prometheus to JSON
+	PreprocPrometheusToJSON = "23"
+	// PreprocSNMPWalkValue SNMP walk value, Zabbix 6.4+
+	PreprocSNMPWalkValue = "28"
+	// PreprocSNMPWalkToJSON SNMP walk to JSON, Zabbix 6.4+
+	PreprocSNMPWalkToJSON = "29"
+)
+
+// SNMPValueFormat conversion applied to SNMP walk values
+type SNMPValueFormat int
+
+const (
+	// SNMPUnchanged keep the value unchanged (default)
+	SNMPUnchanged SNMPValueFormat = 0
+	// SNMPUTF8FromHex convert Hex-STRING to UTF-8
+	SNMPUTF8FromHex SNMPValueFormat = 1
+	// SNMPMACFromHex convert Hex-STRING to MAC
+	SNMPMACFromHex SNMPValueFormat = 2
+	// SNMPIntegerFromBits convert BITS to integer
+	SNMPIntegerFromBits SNMPValueFormat = 3
+)
+
+// prometheusFunctions are the aggregations a Prometheus pattern step can output
+var prometheusFunctions = map[string]bool{"sum": true, "min": true, "max": true, "avg": true, "count": true}
+
+// PrometheusPatternStep Builds a Prometheus pattern step in the 6.0+ format.
+// output is empty for the metric value, an aggregation function
+// (sum, min, max, avg, count) or the name of the label to return.
+func PrometheusPatternStep(pattern, output string) Preprocessor {
+	mode := "value"
+	if output != "" {
+		mode = "label"
+		if prometheusFunctions[output] {
+			mode = "function"
+		}
+	}
+	return Preprocessor{
+		Type:         PreprocPrometheusPattern,
+		Params:       strings.Join([]string{pattern, mode, output}, "\n"),
+		ErrorHandler: "0",
+	}
+}
+
+// SNMPWalkValueStep Builds a step extracting the value of oid from an SNMP walk, Zabbix 6.4+
+func SNMPWalkValueStep(oid string, format SNMPValueFormat) Preprocessor {
+	return Preprocessor{
+		Type:         PreprocSNMPWalkValue,
+		Params:       fmt.Sprintf("%s\n%d", oid, format),
+		ErrorHandler: "0",
+	}
+}
+
+// SNMPWalkField is a field of the objects built by SNMPWalkToJSONStep
+type SNMPWalkField struct {
+	// Name is the LLD macro, like {#IFNAME}
+	Name   string
+	OID    string
+	Format SNMPValueFormat
+}
+
+// SNMPWalkToJSONStep Builds a step converting an SNMP walk to a JSON array for discovery, Zabbix 6.4+
+func SNMPWalkToJSONStep(fields ...SNMPWalkField) Preprocessor {
+	lines := make([]string, 0, 3*len(fields))
+	for _, f := range fields {
+		lines = append(lines, f.Name, f.OID, fmt.Sprint(int(f.Format)))
+	}
+	return Preprocessor{
+		Type:         PreprocSNMPWalkToJSON,
+		Params:       strings.Join(lines, "\n"),
+		ErrorHandler: "0",
+	}
+}
@@ -0,0 +1,29 @@
+package zabbix_test
+
+import (
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestPreprocessingSteps(t *testing.T) {
+	tests := []struct {
+		step        zapi.Preprocessor
+		typ, params string
+	}{
+		{zapi.PrometheusPatternStep(`wmi_os_physical_memory_free_bytes`, ""), "22", "wmi_os_physical_memory_free_bytes\nvalue\n"},
+		{zapi.PrometheusPatternStep(`http_requests_total{code="200"}`, "sum"), "22", "http_requests_total{code=\"200\"}\nfunction\nsum"},
+		{zapi.PrometheusPatternStep(`node_uname_info`, "release"), "22", "node_uname_info\nlabel\nrelease"},
+		{zapi.SNMPWalkValueStep("1.3.6.1.2.1.2.2.1.6.1", zapi.SNMPMACFromHex), "28", "1.3.6.1.2.1.2.2.1.6.1\n2"},
+		{zapi.SNMPWalkToJSONStep(
+			zapi.SNMPWalkField{Name: "{#IFNAME}", OID: "1.3.6.1.2.1.2.2.1.2"},
+			zapi.SNMPWalkField{Name: "{#IFALIAS}", OID: "1.3.6.1.2.1.31.1.1.1.18", Format: zapi.SNMPUTF8FromHex},
+		), "29", "{#IFNAME}\n1.3.6.1.2.1.2.2.1.2\n0\n{#IFALIAS}\n1.3.6.1.2.1.31.1.1.1.18\n1"},
+	}
+
+	for _, test := range tests {
+		if test.step.Type != test.typ || test.step.Params != test.params {
+			t.Errorf("Expected type %s params %q, got %s %q", test.typ, test.params, test.step.Type, test.step.Params)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package zabbix
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SuppressionData tells which maintenance suppresses a problem
+type SuppressionData struct {
+	MaintenanceID string
+	// SuppressUntil is zero when the problem is suppressed indefinitely
+	SuppressUntil time.Time
+}
+
+// UnmarshalJSON decodes the unix time of "suppress_until"
+func (d *SuppressionData) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		MaintenanceID string `json:"maintenanceid"`
+		SuppressUntil string `json:"suppress_until"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	d.MaintenanceID = raw.MaintenanceID
+	d.SuppressUntil = unixTime(raw.SuppressUntil)
+	return nil
+}
+
+// ProblemEvent represent Zabbix problem object, named apart from the Problem trigger value
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/problem/object
+type ProblemEvent struct {
+	EventID       string `json:"eventid"`
+	Source        string `json:"source"`
+	Object        string `json:"object"`
+	ObjectID      string `json:"objectid"`
+	Clock         string `json:"clock"`
+	NS            string `json:"ns"`
+	REventID      string `json:"r_eventid"`
+	RClock        string `json:"r_clock"`
+	RNS           string `json:"r_ns"`
+	CorrelationID string `json:"correlationid"`
+	UserID        string `json:"userid"`
+	// Name is the resolved problem name shown to operators
+	Name         string       `json:"name"`
+	Acknowledged string       `json:"acknowledged"`
+	Severity     SeverityType `json:"severity,string"`
+	Suppressed   string       `json:"suppressed"`
+	// OpData is the resolved operational data of the problem
+	OpData          string            `json:"opdata"`
+	Tags            Tags              `json:"tags,omitempty"`
+	SuppressionData []SuppressionData `json:"suppression_data,omitempty"`
+}
+
+// SuppressedUntil returns when the last maintenance suppressing the problem ends,
+// zero time if one suppresses it indefinitely. ok is false when the problem is not suppressed.
+// Needs the suppression data selected, as done by ProblemsGet.
+func (p ProblemEvent) SuppressedUntil() (until time.Time, ok bool) {
+	for _, d := range p.SuppressionData {
+		if d.SuppressUntil.IsZero() {
+			return time.Time{}, true
+		}
+		if d.SuppressUntil.After(until) {
+			until = d.SuppressUntil
+		}
+	}
+	return until, len(p.SuppressionData) > 0
+}
+
+// Problems is an array of ProblemEvent
+type Problems []ProblemEvent
+
+// ProblemsGet Wrapper for problem.get
+// Tags and suppression data are selected unless asked otherwise.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/problem/get
+func (api *API) ProblemsGet(params Params) (res Problems, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	if _, present := params["selectTags"]; !present {
+		params["selectTags"] = "extend"
+	}
+	if _, present := params["selectSuppressionData"]; !present {
+		params["selectSuppressionData"] = "extend"
+	}
+	err = api.CallWithErrorParse("problem.get", params, &res)
+	return
+}
+
+// ProblemGetOptions typed parameters for problem.get
+// Problems are sorted newest first, by eventid as problem.get cannot sort by clock,
+// unless a sort field is set. Tags and suppression data are selected.
+type ProblemGetOptions struct {
+	GetOptions
+	EventIDs   []string
+	ObjectIDs  []string
+	HostIDs    []string
+	GroupIDs   []string
+	Severities []SeverityType
+	// Recent also returns the problems resolved recently
+	Recent bool
+}
+
+func (o ProblemGetOptions) params() Params {
+	params := o.GetOptions.withDefaultSort("eventid").params()
+	params.setIDs("eventids", o.EventIDs)
+	params.setIDs("objectids", o.ObjectIDs)
+	params.setIDs("hostids", o.HostIDs)
+	params.setIDs("groupids", o.GroupIDs)
+	if len(o.Severities) > 0 {
+		params["severities"] = o.Severities
+	}
+	if o.Recent {
+		params["recent"] = true
+	}
+	return params
+}
+
+// ProblemsGetWithOptions Wrapper for problem.get using typed options
+func (api *API) ProblemsGetWithOptions(opts ProblemGetOptions) (res Problems, err error) {
+	if res, err = api.ProblemsGet(opts.params()); err == nil {
+		err = opts.truncated(len(res))
+	}
+	return
+}
+
+// ProblemsGetByHostIds Gets the problems of the hosts
+func (api *API) ProblemsGetByHostIds(ids []string) (res Problems, err error) {
+	return api.ProblemsGet(Params{"hostids": ids})
+}
+
+// ProblemsGetBySeverity Gets the problems of severity min or higher
+func (api *API) ProblemsGetBySeverity(min SeverityType) (res Problems, err error) {
+	severities := []SeverityType{}
+	for s := min; s <= Critical; s++ {
+		severities = append(severities, s)
+	}
+	return api.ProblemsGet(Params{"severities": severities})
+}
+
+// AcknowledgeProblemsByTag Acknowledges in one event.acknowledge call the problems having the tag,
+// with the value or any value if empty. action is the event.acknowledge action bitmask,
+// adding the message when one is given. Returns the ids of the events acknowledged.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/event/acknowledge
+func (api *API) AcknowledgeProblemsByTag(tag, value, message string, action int) (eventIDs []string, err error) {
+	// refuse before reading anything
+	if api.Config.ReadOnly {
+		return nil, ErrReadOnly
+	}
+
+	// operator 1 is "equals", 0 "like" matching any value when empty
+	operator := 1
+	if value == "" {
+		operator = 0
+	}
+	var problems Problems
+	err = api.CallWithErrorParse("problem.get", Params{
+		"output": []string{"eventid"},
+		"tags":   []map[string]interface{}{{"tag": tag, "value": value, "operator": operator}},
+	}, &problems)
+	if err != nil || len(problems) == 0 {
+		return
+	}
+
+	ids := make([]string, len(problems))
+	for i, p := range problems {
+		ids[i] = p.EventID
+	}
+	return api.acknowledge(ids, AckAction(action), message, nil)
+}
+
+// CloseStaleProblems Closes the trigger problems started more than olderThan ago whose
+// trigger allows manual close, adding the message when one is given, in chunks.
+// Returns the ids of the events closed.
+func (api *API) CloseStaleProblems(olderThan time.Duration, message string) (eventIDs []string, err error) {
+	// refuse before reading anything
+	if api.Config.ReadOnly {
+		return nil, ErrReadOnly
+	}
+
+	var problems Problems
+	err = api.CallWithErrorParse("problem.get", Params{
+		"output":    []string{"eventid", "objectid"},
+		"source":    EventSourceTrigger,
+		"object":    0,
+		"time_till": time.Now().Add(-olderThan).Unix(),
+	}, &problems)
+	if err != nil || len(problems) == 0 {
+		return
+	}
+	triggerIDs := make([]string, len(problems))
+	for i, p := range problems {
+		triggerIDs[i] = p.ObjectID
+	}
+	triggers, err := api.TriggersGet(Params{
+		"output":     []string{"triggerid", "manual_close"},
+		"triggerids": triggerIDs,
+		"filter":     map[string]interface{}{"manual_close": 1},
+	})
+	if err != nil {
+		return
+	}
+	closable := map[string]bool{}
+	for _, t := range triggers {
+		closable[t.TriggerID] = t.ManualClose == 1
+	}
+	ids := []string{}
+	for _, p := range problems {
+		if closable[p.ObjectID] {
+			ids = append(ids, p.EventID)
+		}
+	}
+
+	err = api.chunked(ids, func(chunk []string) error {
+		closed, err := api.acknowledge(chunk, AckActionClose, message, nil)
+		eventIDs = append(eventIDs, closed...)
+		return err
+	})
+	return
+}
+
+// EventAcknowledge Wrapper for event.acknowledge
+// action is a bitmask of AckAction, adding the message when one is given.
+// Changing the severity needs EventAcknowledgeWithSeverity.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/event/acknowledge
+func (api *API) EventAcknowledge(eventIDs []string, action AckAction, message string) (err error) {
+	if action&AckActionChangeSeverity != 0 {
+		return fmt.Errorf("Changing the severity of events needs EventAcknowledgeWithSeverity")
+	}
+	_, err = api.acknowledge(eventIDs, action, message, nil)
+	return
+}
+
+// EventAcknowledgeWithSeverity Wrapper for event.acknowledge changing the severity of the events
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/event/acknowledge
+func (api *API) EventAcknowledgeWithSeverity(eventIDs []string, action AckAction, message string, severity SeverityType) (err error) {
+	_, err = api.acknowledge(eventIDs, action|AckActionChangeSeverity, message, &severity)
+	return
+}
+
+// acknowledge calls event.acknowledge and returns the ids of the events acknowledged
+func (api *API) acknowledge(eventIDs []string, action AckAction, message string, severity *SeverityType) (res []string, err error) {
+	params := Params{"eventids": eventIDs, "action": action}
+	if message != "" {
+		params["action"] = action | AckActionAddMessage
+		params["message"] = message
+	}
+	if severity != nil {
+		params["severity"] = *severity
+	}
+	// event ids are returned as numbers or strings depending on the version
+	var result struct {
+		EventIDs []json.RawMessage `json:"eventids"`
+	}
+	if err = api.CallWithErrorParse("event.acknowledge", params, &result); err != nil {
+		return
+	}
+	for _, id := range result.EventIDs {
+		res = append(res, strings.Trim(string(id), `"`))
+	}
+	return
+}
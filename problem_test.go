@@ -0,0 +1,183 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestProblemsGet(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return []map[string]interface{}{{
+			"eventid":  "100",
+			"objectid": "13",
+			"name":     "High CPU load on web01",
+			"opdata":   "Current load: 12.5",
+			"severity": "4",
+			"tags":     []map[string]string{{"tag": "scope", "value": "performance"}},
+		}}, nil
+	})
+
+	problems, err := api.ProblemsGet(zapi.Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 {
+		t.Fatalf("Bad problems: %#v", problems)
+	}
+	p := problems[0]
+	if p.Name != "High CPU load on web01" || p.OpData != "Current load: 12.5" || p.Severity != zapi.High || len(p.Tags) != 1 {
+		t.Errorf("Bad problem: %#v", p)
+	}
+}
+
+func TestProblemSuppressedUntil(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return []map[string]interface{}{
+			{"eventid": "100", "suppressed": "1", "suppression_data": []map[string]string{
+				{"maintenanceid": "1", "suppress_until": "1472511600"},
+				{"maintenanceid": "2", "suppress_until": "1472515200"},
+			}},
+			{"eventid": "101", "suppressed": "1", "suppression_data": []map[string]string{
+				{"maintenanceid": "3", "suppress_until": "0"},
+			}},
+			{"eventid": "102", "suppressed": "0", "suppression_data": []interface{}{}},
+		}, nil
+	})
+
+	problems, err := api.ProblemsGet(zapi.Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 3 {
+		t.Fatalf("Bad problems: %#v", problems)
+	}
+	if until, ok := problems[0].SuppressedUntil(); !ok || until.Unix() != 1472515200 {
+		t.Errorf("Bad suppression end: %s %v", until, ok)
+	}
+	if until, ok := problems[1].SuppressedUntil(); !ok || !until.IsZero() {
+		t.Errorf("Expected indefinite suppression: %s %v", until, ok)
+	}
+	if _, ok := problems[2].SuppressedUntil(); ok {
+		t.Error("Expected problem not suppressed")
+	}
+}
+
+func TestAcknowledgeProblemsByTag(t *testing.T) {
+	var problemParams, ackParams map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "problem.get":
+			json.Unmarshal(params, &problemParams)
+			return []map[string]string{{"eventid": "20427"}, {"eventid": "20428"}, {"eventid": "12345678"}}, nil
+		case "event.acknowledge":
+			json.Unmarshal(params, &ackParams)
+			return json.RawMessage(`{"eventids": [20427, "20428", 12345678]}`), nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	ids, err := api.AcknowledgeProblemsByTag("service", "web", "Known issue", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[0] != "20427" || ids[1] != "20428" || ids[2] != "12345678" {
+		t.Errorf("Bad event ids: %v", ids)
+	}
+	tags := problemParams["tags"].([]interface{})
+	if tag := tags[0].(map[string]interface{}); tag["tag"] != "service" || tag["value"] != "web" || tag["operator"] != float64(1) {
+		t.Errorf("Bad tag filter: %#v", tags)
+	}
+	if ackParams["action"] != float64(6) || ackParams["message"] != "Known issue" || len(ackParams["eventids"].([]interface{})) != 3 {
+		t.Errorf("Bad acknowledge: %#v", ackParams)
+	}
+
+	readOnly := getMockAPI(t, zapi.Config{ReadOnly: true}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		t.Fatalf("Unexpected call %s in read only mode", method)
+		return nil, nil
+	})
+	if _, err = readOnly.AcknowledgeProblemsByTag("service", "web", "", 2); err != zapi.ErrReadOnly {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestProblemsGetHelpers(t *testing.T) {
+	var sent map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		sent = nil
+		json.Unmarshal(params, &sent)
+		return []map[string]string{{"eventid": "100", "severity": "4"}}, nil
+	})
+
+	if _, err := api.ProblemsGetByHostIds([]string{"10084"}); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := sent["hostids"].([]interface{}); len(ids) != 1 || ids[0] != "10084" || sent["selectTags"] != "extend" {
+		t.Errorf("Bad params: %v", sent)
+	}
+
+	problems, err := api.ProblemsGetBySeverity(zapi.Average)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if severities, _ := sent["severities"].([]interface{}); len(severities) != 3 || severities[0] != float64(zapi.Average) || severities[2] != float64(zapi.Critical) {
+		t.Errorf("Bad severities: %v", sent["severities"])
+	}
+	if len(problems) != 1 || problems[0].Severity != zapi.High {
+		t.Errorf("Bad problems: %#v", problems)
+	}
+}
+
+func TestCloseStaleProblems(t *testing.T) {
+	var acked map[string]interface{}
+	var till float64
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var p map[string]interface{}
+		json.Unmarshal(params, &p)
+		switch method {
+		case "problem.get":
+			till, _ = p["time_till"].(float64)
+			return []map[string]string{
+				{"eventid": "101", "objectid": "13"},
+				{"eventid": "102", "objectid": "14"},
+				{"eventid": "103", "objectid": "13"},
+			}, nil
+		case "trigger.get":
+			return []map[string]string{
+				{"triggerid": "13", "manual_close": "1"},
+				{"triggerid": "14", "manual_close": "0"},
+			}, nil
+		case "event.acknowledge":
+			acked = p
+			return map[string]interface{}{"eventids": []int{101, 103}}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	closed, err := api.CloseStaleProblems(7*24*time.Hour, "Stale, closed by cleanup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(closed, []string{"101", "103"}) {
+		t.Errorf("Bad events closed: %v", closed)
+	}
+	if limit := time.Now().Add(-7 * 24 * time.Hour).Unix(); int64(till) < limit-5 || int64(till) > limit {
+		t.Errorf("Bad time_till %v, expected about %d", till, limit)
+	}
+	if !reflect.DeepEqual(acked["eventids"], []interface{}{"101", "103"}) || acked["action"] != float64(zapi.AckActionClose|zapi.AckActionAddMessage) {
+		t.Errorf("Bad acknowledge: %v", acked)
+	}
+
+	api = getMockAPI(t, zapi.Config{ReadOnly: true}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+	if _, err = api.CloseStaleProblems(time.Hour, ""); err != zapi.ErrReadOnly {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
@@ -1,22 +1,292 @@
 package zabbix
 
-// Proxy represent Zabbix proxy object
-// https://www.zabbix.com/documentation/3.2/manual/api/reference/proxy/object
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// ProxyStatus Type of the proxy before Zabbix 7.0, see ProxyOperatingMode
+// see "status" in https://www.zabbix.com/documentation/6.0/manual/api/reference/proxy/object
+type ProxyStatus int
+
+const (
+	// ProxyStatusActive active proxy
+	ProxyStatusActive ProxyStatus = 5
+	// ProxyStatusPassive passive proxy
+	ProxyStatusPassive ProxyStatus = 6
+)
+
+// ProxyInterface Interface the server connects to a passive proxy through
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/proxy/object#proxy-interface
+type ProxyInterface struct {
+	IP    string `json:"ip,omitempty"`
+	DNS   string `json:"dns,omitempty"`
+	UseIP string `json:"useip,omitempty"`
+	Port  string `json:"port,omitempty"`
+}
+
+// Proxy represent Zabbix proxy object, the Zabbix 7.0 fields being read into
+// the older ones by ProxiesGet, see Proxy7 for the 7.0 object itself
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/proxy/object
 type Proxy struct {
 	ProxyID string `json:"proxyid,omitempty"`
-	Host    string `json:"host"`
-	// add rest later
+	// Host is the name of the proxy, read from name since Zabbix 7.0
+	Host        string      `json:"host,omitempty"`
+	Status      ProxyStatus `json:"status,omitempty,string"`
+	Description string      `json:"description,omitempty"`
+	// ProxyAddress is the comma delimited list of addresses an active proxy may connect from
+	ProxyAddress string `json:"proxy_address,omitempty"`
+	// Interface of a passive proxy, nil for active ones
+	Interface    *ProxyInterface `json:"-"`
+	RawInterface json.RawMessage `json:"interface,omitempty"`
+	// LastAccess is read only, the unix time the proxy last contacted the server
+	LastAccess string `json:"lastaccess,omitempty"`
+
+	// Zabbix 7.0 fields, moved to the fields above by ProxiesGet
+	RawName             string `json:"name,omitempty"`
+	RawOperatingMode    string `json:"operating_mode,omitempty"`
+	RawAllowedAddresses string `json:"allowed_addresses,omitempty"`
+	RawAddress          string `json:"address,omitempty"`
+	RawPort             string `json:"port,omitempty"`
 }
 
 // Proxies is an array of Proxy
 type Proxies []Proxy
 
+// LastAccessTime returns the time the proxy last contacted the server, zero if never.
+func (p Proxy) LastAccessTime() time.Time {
+	return unixTime(p.LastAccess)
+}
+
 // ProxiesGet Wrapper for proxy.get
-// https://www.zabbix.com/documentation/3.2/manual/api/reference/proxy/get
+// The interface of passive proxies is selected before Zabbix 7.0 and the fields
+// of 7.0 proxies are moved to the older ones.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/proxy/get
 func (api *API) ProxiesGet(params Params) (res Proxies, err error) {
 	if _, present := params["output"]; !present {
 		params["output"] = "extend"
 	}
-	err = api.CallWithErrorParse("proxy.get", params, &res)
+	if _, present := params["selectInterface"]; !present {
+		if v, err := api.ServerVersion(); err == nil && v < 70000 {
+			params["selectInterface"] = "extend"
+		}
+	}
+	if err = api.CallWithErrorParse("proxy.get", params, &res); err != nil {
+		return
+	}
+
+	for i, p := range res {
+		if p.RawName != "" {
+			res[i].Host, res[i].RawName = p.RawName, ""
+		}
+		if p.RawAllowedAddresses != "" {
+			res[i].ProxyAddress, res[i].RawAllowedAddresses = p.RawAllowedAddresses, ""
+		}
+		if mode, err := strconv.Atoi(p.RawOperatingMode); err == nil {
+			res[i].Status = ProxyStatusActive
+			if ProxyOperatingMode(mode) == ProxyPassive {
+				res[i].Status = ProxyStatusPassive
+				in := &ProxyInterface{DNS: p.RawAddress, UseIP: "0", Port: p.RawPort}
+				if net.ParseIP(p.RawAddress) != nil {
+					in.IP, in.DNS, in.UseIP = p.RawAddress, "", "1"
+				}
+				res[i].Interface = in
+			}
+		}
+		res[i].RawOperatingMode, res[i].RawAddress, res[i].RawPort = "", "", ""
+
+		// active proxies have an empty array
+		if len(p.RawInterface) > 0 && string(p.RawInterface) != "[]" {
+			in := &ProxyInterface{}
+			if err = json.Unmarshal(p.RawInterface, in); err != nil {
+				return nil, err
+			}
+			res[i].Interface = in
+		}
+		res[i].RawInterface = nil
+	}
+	return
+}
+
+// ProxyGetByID Gets proxy by Id only if there is exactly 1 matching proxy.
+func (api *API) ProxyGetByID(id string) (res *Proxy, err error) {
+	proxies, err := api.ProxiesGet(Params{"proxyids": id})
+	if err != nil {
+		return
+	}
+	if len(proxies) != 1 {
+		e := ExpectedOneResult(len(proxies))
+		return nil, &e
+	}
+	return &proxies[0], nil
+}
+
+// prepProxies returns the proxies to send to server version v: read only fields are
+// cleared, the interface of passive proxies is checked and Zabbix 7.0 gets its own fields
+func prepProxies(proxies Proxies, v int, create bool) (res Proxies, err error) {
+	res = make(Proxies, len(proxies))
+	for i, p := range proxies {
+		if create && p.Status != ProxyStatusActive && p.Status != ProxyStatusPassive {
+			return nil, fmt.Errorf("Proxy %s: status must be ProxyStatusActive or ProxyStatusPassive", p.Host)
+		}
+		if p.Status == ProxyStatusPassive && p.Interface == nil {
+			return nil, fmt.Errorf("Proxy %s: passive proxies require an Interface", p.Host)
+		}
+		p.LastAccess, p.RawInterface = "", nil
+		if p.Status != ProxyStatusPassive {
+			p.Interface = nil
+		}
+
+		if v < 70000 {
+			if p.Interface != nil {
+				b, _ := json.Marshal(p.Interface)
+				p.RawInterface = json.RawMessage(b)
+			}
+			res[i] = p
+			continue
+		}
+		p.RawName, p.Host = p.Host, ""
+		p.RawAllowedAddresses, p.ProxyAddress = p.ProxyAddress, ""
+		switch p.Status {
+		case ProxyStatusActive:
+			p.RawOperatingMode = strconv.Itoa(int(ProxyActive))
+		case ProxyStatusPassive:
+			p.RawOperatingMode = strconv.Itoa(int(ProxyPassive))
+			p.RawAddress, p.RawPort = p.Interface.DNS, p.Interface.Port
+			if p.Interface.UseIP != "0" && p.Interface.IP != "" {
+				p.RawAddress = p.Interface.IP
+			}
+		}
+		p.Status, p.Interface = 0, nil
+		res[i] = p
+	}
 	return
 }
+
+// ProxiesCreate Wrapper for proxy.create
+// Passive proxies need their Interface, sent as address and port on Zabbix 7.0
+// where the other fields are also renamed, see Proxy7 for the 7.0 only fields.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/proxy/create
+func (api *API) ProxiesCreate(proxies Proxies) (err error) {
+	v, err := api.ServerVersion()
+	if err != nil {
+		return
+	}
+	sent, err := prepProxies(proxies, v, true)
+	if err != nil {
+		return
+	}
+	response, err := api.CallWithError("proxy.create", sent)
+	if err != nil {
+		return
+	}
+
+	proxyids, err := resultIDs(response, "proxyids")
+	if err != nil {
+		return
+	}
+	if len(proxyids) != len(proxies) {
+		return &ExpectedMore{len(proxies), len(proxyids)}
+	}
+	for i, id := range proxyids {
+		proxies[i].ProxyID = id
+	}
+	return
+}
+
+// ProxiesUpdate Wrapper for proxy.update, see ProxiesCreate
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/proxy/update
+func (api *API) ProxiesUpdate(proxies Proxies) (err error) {
+	v, err := api.ServerVersion()
+	if err != nil {
+		return
+	}
+	sent, err := prepProxies(proxies, v, false)
+	if err != nil {
+		return
+	}
+	_, err = api.CallWithError("proxy.update", sent)
+	return
+}
+
+// ProxiesDeleteByIds Wrapper for proxy.delete
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/proxy/delete
+func (api *API) ProxiesDeleteByIds(ids []string) (err error) {
+	response, err := api.CallWithError("proxy.delete", ids)
+	if err != nil {
+		return
+	}
+
+	proxyids, err := resultIDs(response, "proxyids")
+	if err == nil && len(ids) != len(proxyids) {
+		err = &ExpectedMore{len(ids), len(proxyids)}
+	}
+	return
+}
+
+// ProxiesDelete Wrapper for proxy.delete
+// Cleans ProxyID in all proxies elements if call succeed.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/proxy/delete
+func (api *API) ProxiesDelete(proxies Proxies) (err error) {
+	ids := make([]string, len(proxies))
+	for i, proxy := range proxies {
+		ids[i] = proxy.ProxyID
+	}
+
+	err = api.ProxiesDeleteByIds(ids)
+	if err == nil {
+		for i := range proxies {
+			proxies[i].ProxyID = ""
+		}
+	}
+	return
+}
+
+// MoveHostsToProxy Sets the hosts monitored by the proxy, by the server when proxyID is "0".
+// Zabbix 7.0+ gets proxyid and monitored_by, older servers proxy_hostid.
+func (api *API) MoveHostsToProxy(hostIDs []string, proxyID string) (err error) {
+	v, err := api.ServerVersion()
+	if err != nil {
+		return
+	}
+	fields := Params{"proxy_hostid": proxyID}
+	if v >= 70000 {
+		by := MonitoredByProxy
+		if proxyID == "0" {
+			by = MonitoredByServer
+		}
+		fields = Params{"proxyid": proxyID, "monitored_by": by}
+	}
+	return api.massUpdateHosts(hostIDs, fields)
+}
+
+// MoveHostsToProxyGroup Sets the hosts monitored by the proxy group, Zabbix 7.0+
+func (api *API) MoveHostsToProxyGroup(hostIDs []string, proxyGroupID string) (err error) {
+	v, err := api.ServerVersion()
+	if err != nil {
+		return
+	}
+	if v < 70000 {
+		return fmt.Errorf("proxy groups need Zabbix 7.0, server is %d", v)
+	}
+	return api.massUpdateHosts(hostIDs, Params{"proxy_groupid": proxyGroupID, "monitored_by": MonitoredByProxyGroup})
+}
+
+// massUpdateHosts calls host.massupdate setting fields on the hosts, in chunks
+func (api *API) massUpdateHosts(hostIDs []string, fields Params) (err error) {
+	return api.chunked(hostIDs, func(chunk []string) error {
+		hosts := make([]map[string]string, 0, len(chunk))
+		for _, id := range chunk {
+			hosts = append(hosts, map[string]string{"hostid": id})
+		}
+		params := Params{"hosts": hosts}
+		for k, v := range fields {
+			params[k] = v
+		}
+		_, err := api.CallWithError("host.massupdate", params)
+		return err
+	})
+}
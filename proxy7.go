@@ -0,0 +1,88 @@
+package zabbix
+
+import "time"
+
+type (
+	// ProxyOperatingMode Operating mode of a 7.0 proxy
+	// see "operating_mode" in https://www.zabbix.com/documentation/7.0/manual/api/reference/proxy/object
+	ProxyOperatingMode int
+
+	// ProxyCompatibility (readonly) Version compatibility of a 7.0 proxy with the server
+	ProxyCompatibility int
+)
+
+const (
+	// ProxyActive active proxy
+	ProxyActive ProxyOperatingMode = 0
+	// ProxyPassive passive proxy
+	ProxyPassive ProxyOperatingMode = 1
+)
+
+const (
+	// ProxyCompatibilityUndefined (default) undefined
+	ProxyCompatibilityUndefined ProxyCompatibility = 0
+	// ProxyCompatibilityCurrent proxy and server have the same major version
+	ProxyCompatibilityCurrent ProxyCompatibility = 1
+	// ProxyCompatibilityOutdated proxy is older than server but still supported
+	ProxyCompatibilityOutdated ProxyCompatibility = 2
+	// ProxyCompatibilityUnsupported proxy version is not supported by server
+	ProxyCompatibilityUnsupported ProxyCompatibility = 3
+)
+
+// Proxy7 represent Zabbix 7.0 proxy object
+// https://www.zabbix.com/documentation/7.0/manual/api/reference/proxy/object
+type Proxy7 struct {
+	ProxyID       string             `json:"proxyid,omitempty"`
+	Name          string             `json:"name"`
+	OperatingMode ProxyOperatingMode `json:"operating_mode,string"`
+	Description   string             `json:"description,omitempty"`
+	ProxyGroupID  string             `json:"proxy_groupid,omitempty"`
+	LocalAddress  string             `json:"local_address,omitempty"`
+	LocalPort     string             `json:"local_port,omitempty"`
+
+	// AllowedAddresses is the comma delimited list of addresses an active proxy may connect from
+	AllowedAddresses string `json:"allowed_addresses,omitempty"`
+	// Address and Port of a passive proxy
+	Address string `json:"address,omitempty"`
+	Port    string `json:"port,omitempty"`
+
+	// Fields below are read only
+	LastAccess    string             `json:"lastaccess,omitempty"`
+	Version       string             `json:"version,omitempty"`
+	Compatibility ProxyCompatibility `json:"compatibility,omitempty,string"`
+}
+
+// Proxies7 is an array of Proxy7
+type Proxies7 []Proxy7
+
+// LastAccessTime returns the time the proxy last contacted the server, zero if never.
+func (p Proxy7) LastAccessTime() time.Time {
+	return unixTime(p.LastAccess)
+}
+
+// Proxies7Get Wrapper for proxy.get on Zabbix 7.0
+// https://www.zabbix.com/documentation/7.0/manual/api/reference/proxy/get
+func (api *API) Proxies7Get(params Params) (res Proxies7, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("proxy.get", params, &res)
+	return
+}
+
+// Proxy7GetStale Gets proxies which did not contact the server for longer than threshold.
+// Proxies which never contacted the server are included.
+func (api *API) Proxy7GetStale(threshold time.Duration) (res Proxies7, err error) {
+	proxies, err := api.Proxies7Get(Params{})
+	if err != nil {
+		return
+	}
+
+	limit := time.Now().Add(-threshold)
+	for _, p := range proxies {
+		if p.LastAccessTime().Before(limit) {
+			res = append(res, p)
+		}
+	}
+	return
+}
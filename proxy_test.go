@@ -0,0 +1,173 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestProxy7ReadOnlyFields(t *testing.T) {
+	raw := `{"proxyid":"10","name":"edge","operating_mode":"0","allowed_addresses":"10.0.0.1,10.0.0.2",
+		"lastaccess":"1700000000","version":"70002","compatibility":"1"}`
+
+	var p zapi.Proxy7
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.AllowedAddresses != "10.0.0.1,10.0.0.2" || p.Version != "70002" || p.Compatibility != zapi.ProxyCompatibilityCurrent {
+		t.Errorf("Bad proxy: %#v", p)
+	}
+	if p.LastAccessTime().Unix() != 1700000000 {
+		t.Errorf("Bad last access: %s", p.LastAccessTime())
+	}
+}
+
+func TestMoveHostsToProxy(t *testing.T) {
+	for _, test := range []struct {
+		version  int
+		group    bool
+		expected map[string]interface{}
+	}{
+		{60000, false, map[string]interface{}{"proxy_hostid": "5"}},
+		{70000, false, map[string]interface{}{"proxyid": "5", "monitored_by": float64(zapi.MonitoredByProxy)}},
+		{70000, true, map[string]interface{}{"proxy_groupid": "5", "monitored_by": float64(zapi.MonitoredByProxyGroup)}},
+	} {
+		var sent map[string]interface{}
+		api := getMockAPI(t, zapi.Config{Version: test.version}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+			if method != "host.massupdate" {
+				t.Fatalf("Unexpected method %s", method)
+			}
+			json.Unmarshal(params, &sent)
+			return map[string][]string{"hostids": {"1", "2"}}, nil
+		})
+
+		var err error
+		if test.group {
+			err = api.MoveHostsToProxyGroup([]string{"1", "2"}, "5")
+		} else {
+			err = api.MoveHostsToProxy([]string{"1", "2"}, "5")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		hosts := []interface{}{map[string]interface{}{"hostid": "1"}, map[string]interface{}{"hostid": "2"}}
+		if !reflect.DeepEqual(sent["hosts"], hosts) {
+			t.Errorf("%d: bad hosts %v", test.version, sent["hosts"])
+		}
+		delete(sent, "hosts")
+		if !reflect.DeepEqual(sent, test.expected) {
+			t.Errorf("%d: expected %v, got %v", test.version, test.expected, sent)
+		}
+	}
+
+	api := getMockAPI(t, zapi.Config{Version: 60000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+	if err := api.MoveHostsToProxyGroup([]string{"1"}, "5"); err == nil {
+		t.Error("Expected proxy groups refused before 7.0")
+	}
+}
+
+func TestProxiesGet(t *testing.T) {
+	responses := map[int][]map[string]interface{}{
+		60000: {
+			{"proxyid": "10", "host": "edge", "status": "5", "proxy_address": "10.0.0.1", "lastaccess": "1700000000", "interface": []string{}},
+			{"proxyid": "11", "host": "dmz", "status": "6", "lastaccess": "0",
+				"interface": map[string]string{"interfaceid": "3", "hostid": "11", "useip": "1", "ip": "192.168.1.5", "dns": "", "port": "10051"}},
+		},
+		70000: {
+			{"proxyid": "10", "name": "edge", "operating_mode": "0", "allowed_addresses": "10.0.0.1", "address": "127.0.0.1", "port": "10051", "lastaccess": "1700000000"},
+			{"proxyid": "11", "name": "dmz", "operating_mode": "1", "address": "192.168.1.5", "port": "10051", "lastaccess": "0"},
+		},
+	}
+	for version, response := range responses {
+		var sent map[string]interface{}
+		api := getMockAPI(t, zapi.Config{Version: version}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+			if method != "proxy.get" {
+				t.Fatalf("Unexpected method %s", method)
+			}
+			json.Unmarshal(params, &sent)
+			return response, nil
+		})
+
+		proxies, err := api.ProxiesGet(zapi.Params{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := sent["selectInterface"]; ok != (version < 70000) {
+			t.Errorf("%d: bad params %v", version, sent)
+		}
+		expected := zapi.Proxies{
+			{ProxyID: "10", Host: "edge", Status: zapi.ProxyStatusActive, ProxyAddress: "10.0.0.1", LastAccess: "1700000000"},
+			{ProxyID: "11", Host: "dmz", Status: zapi.ProxyStatusPassive, LastAccess: "0",
+				Interface: &zapi.ProxyInterface{IP: "192.168.1.5", UseIP: "1", Port: "10051"}},
+		}
+		if !reflect.DeepEqual(proxies, expected) {
+			t.Errorf("%d: bad proxies\n%#v\n%#v", version, proxies, expected)
+		}
+		if proxies[0].LastAccessTime().Unix() != 1700000000 || !proxies[1].LastAccessTime().IsZero() {
+			t.Errorf("%d: bad last access", version)
+		}
+
+		proxy, err := api.ProxyGetByID("10")
+		if err == nil {
+			t.Errorf("%d: expected error for several proxies, got %#v", version, proxy)
+		}
+	}
+}
+
+func TestProxiesCreate(t *testing.T) {
+	passive := zapi.Proxy{Host: "dmz", Status: zapi.ProxyStatusPassive,
+		Interface: &zapi.ProxyInterface{IP: "192.168.1.5", UseIP: "1", Port: "10051"}}
+	active := zapi.Proxy{Host: "edge", Status: zapi.ProxyStatusActive, ProxyAddress: "10.0.0.1"}
+	for _, test := range []struct {
+		version  int
+		expected []map[string]interface{}
+	}{
+		{60000, []map[string]interface{}{
+			{"host": "edge", "status": "5", "proxy_address": "10.0.0.1"},
+			{"host": "dmz", "status": "6", "interface": map[string]interface{}{"ip": "192.168.1.5", "useip": "1", "port": "10051"}},
+		}},
+		{70000, []map[string]interface{}{
+			{"name": "edge", "operating_mode": "0", "allowed_addresses": "10.0.0.1"},
+			{"name": "dmz", "operating_mode": "1", "address": "192.168.1.5", "port": "10051"},
+		}},
+	} {
+		var sent []map[string]interface{}
+		api := getMockAPI(t, zapi.Config{Version: test.version}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+			switch method {
+			case "proxy.create":
+				json.Unmarshal(params, &sent)
+				return map[string][]string{"proxyids": {"10", "11"}}, nil
+			case "proxy.delete":
+				return map[string][]string{"proxyids": {"10", "11"}}, nil
+			}
+			t.Fatalf("Unexpected method %s", method)
+			return nil, nil
+		})
+
+		proxies := zapi.Proxies{active, passive}
+		if err := api.ProxiesCreate(proxies); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(sent, test.expected) {
+			t.Errorf("%d: bad proxies sent %#v", test.version, sent)
+		}
+		if proxies[0].ProxyID != "10" || proxies[1].ProxyID != "11" || proxies[1].Host != "dmz" || proxies[1].Interface == nil {
+			t.Errorf("%d: bad proxies after create %#v", test.version, proxies)
+		}
+
+		if err := api.ProxiesDelete(proxies); err != nil || proxies[0].ProxyID != "" {
+			t.Errorf("%d: bad delete %v %#v", test.version, err, proxies)
+		}
+
+		sent = nil
+		bad := zapi.Proxies{{Host: "dmz", Status: zapi.ProxyStatusPassive}}
+		if err := api.ProxiesCreate(bad); err == nil || sent != nil {
+			t.Errorf("%d: expected error for passive proxy without interface", test.version)
+		}
+	}
+}
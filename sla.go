@@ -0,0 +1,74 @@
+package zabbix
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// SLIResult is the service level of one service over one period, as reported by sla.getsli
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/sla/getsli
+type SLIResult struct {
+	ServiceID   string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Uptime      time.Duration
+	Downtime    time.Duration
+	// SLI is the percentage of uptime
+	SLI float64
+	// Error is the error budget, the downtime left before the SLO is missed, negative once it is
+	Error time.Duration
+}
+
+// sliResponse is the result of sla.getsli, sli holding a row per period and a column per service
+type sliResponse struct {
+	Periods []struct {
+		From int64 `json:"period_from"`
+		To   int64 `json:"period_to"`
+	} `json:"periods"`
+	// service ids are numbers or strings depending on the version
+	ServiceIDs []json.RawMessage `json:"serviceids"`
+	SLI        [][]struct {
+		Uptime      int64   `json:"uptime"`
+		Downtime    int64   `json:"downtime"`
+		SLI         float64 `json:"sli"`
+		ErrorBudget int64   `json:"error_budget"`
+	} `json:"sli"`
+}
+
+// SLAReport Wrapper for sla.getsli, Zabbix 6.0+
+// Returns the service level of the services, all those of the SLA when serviceIDs is empty,
+// over the last periods reporting periods, oldest first, flattened by period then service.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/sla/getsli
+func (api *API) SLAReport(slaID string, serviceIDs []string, periods int) (res []SLIResult, err error) {
+	params := Params{"slaid": slaID}
+	params.setIDs("serviceids", serviceIDs)
+	if periods > 0 {
+		params["periods"] = periods
+	}
+	var sli sliResponse
+	if err = api.CallWithErrorParse("sla.getsli", params, &sli); err != nil {
+		return
+	}
+
+	for i, period := range sli.Periods {
+		if i >= len(sli.SLI) {
+			break
+		}
+		for j, s := range sli.SLI[i] {
+			if j >= len(sli.ServiceIDs) {
+				break
+			}
+			res = append(res, SLIResult{
+				ServiceID:   strings.Trim(string(sli.ServiceIDs[j]), `"`),
+				PeriodStart: time.Unix(period.From, 0),
+				PeriodEnd:   time.Unix(period.To, 0),
+				Uptime:      time.Duration(s.Uptime) * time.Second,
+				Downtime:    time.Duration(s.Downtime) * time.Second,
+				SLI:         s.SLI,
+				Error:       time.Duration(s.ErrorBudget) * time.Second,
+			})
+		}
+	}
+	return
+}
@@ -0,0 +1,50 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestSLAReport(t *testing.T) {
+	var sent map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method != "sla.getsli" {
+			t.Fatalf("Unexpected method %s", method)
+		}
+		json.Unmarshal(params, &sent)
+		var res interface{}
+		json.Unmarshal([]byte(`{
+			"periods": [{"period_from": 1700000000, "period_to": 1700086400}, {"period_from": 1700086400, "period_to": 1700172800}],
+			"serviceids": [2, 5],
+			"sli": [
+				[{"uptime": 86400, "downtime": 0, "sli": 100, "error_budget": 864, "excluded_downtimes": []},
+				 {"uptime": 85400, "downtime": 1000, "sli": 98.84, "error_budget": -136, "excluded_downtimes": []}],
+				[{"uptime": 86400, "downtime": 0, "sli": 100, "error_budget": 864, "excluded_downtimes": []},
+				 {"uptime": 86300, "downtime": 100, "sli": 99.88, "error_budget": 764, "excluded_downtimes": []}]
+			]
+		}`), &res)
+		return res, nil
+	})
+
+	report, err := api.SLAReport("1", []string{"2", "5"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent["slaid"] != "1" || sent["periods"] != float64(2) {
+		t.Errorf("Bad params: %v", sent)
+	}
+	if len(report) != 4 {
+		t.Fatalf("Expected 4 results, got %#v", report)
+	}
+	r := report[1]
+	if r.ServiceID != "5" || r.PeriodStart.Unix() != 1700000000 || r.PeriodEnd.Unix() != 1700086400 ||
+		r.Downtime != 1000*time.Second || r.SLI != 98.84 || r.Error != -136*time.Second {
+		t.Errorf("Bad result: %#v", r)
+	}
+	if report[3].ServiceID != "5" || report[3].PeriodStart.Unix() != 1700086400 || report[3].Uptime != 86300*time.Second {
+		t.Errorf("Bad result: %#v", report[3])
+	}
+}
@@ -0,0 +1,137 @@
+package zabbix
+
+// Has reports whether tags contain tag with the given value
+func (tags Tags) Has(tag, value string) bool {
+	for _, t := range tags {
+		if t.Tag == tag && t.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// with returns tags with tag/value appended unless already present
+func (tags Tags) with(tag, value string) Tags {
+	if tags.Has(tag, value) {
+		return tags
+	}
+	return append(append(Tags{}, tags...), Tag{Tag: tag, Value: value})
+}
+
+// without returns tags minus tag, value is ignored when empty
+func (tags Tags) without(tag, value string) Tags {
+	res := Tags{}
+	for _, t := range tags {
+		if t.Tag == tag && (value == "" || t.Value == value) {
+			continue
+		}
+		res = append(res, t)
+	}
+	return res
+}
+
+// local returns the tags owned by the object, without their read only Automatic flag
+func (tags Tags) local() Tags {
+	if tags == nil {
+		return nil
+	}
+	res := Tags{}
+	for _, t := range tags {
+		if t.Automatic == "1" {
+			continue
+		}
+		t.Automatic = ""
+		res = append(res, t)
+	}
+	return res
+}
+
+// minus returns tags without those also in other
+func (tags Tags) minus(other Tags) Tags {
+	if tags == nil {
+		return nil
+	}
+	res := Tags{}
+	for _, t := range tags {
+		if !other.Has(t.Tag, t.Value) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// inherits reports whether an object linked to templateID may carry template tags
+func inherits(templateID string, tags Tags) bool {
+	return templateID != "" && templateID != "0" && len(tags) != 0
+}
+
+// StripInheritedTags Removes from templated items the tags of their template item,
+// so read items can be written back. ItemsUpdate does it, items need TemplateID.
+func (api *API) StripInheritedTags(items Items) (err error) {
+	ids := []string{}
+	for _, item := range items {
+		if inherits(item.TemplateID, item.Tags) {
+			ids = append(ids, item.TemplateID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	parents, err := api.ItemsGet(Params{"itemids": ids, "output": []string{"itemid"}, "selectTags": "extend"})
+	if err != nil {
+		return
+	}
+	byID := map[string]Tags{}
+	for _, parent := range parents {
+		byID[parent.ItemID] = parent.Tags
+	}
+	for i, item := range items {
+		if tags, ok := byID[item.TemplateID]; ok {
+			items[i].Tags = item.Tags.minus(tags)
+		}
+	}
+	return
+}
+
+// stripInheritedTriggerTags removes from templated triggers the tags of their template trigger
+func (api *API) stripInheritedTriggerTags(triggers Triggers) (err error) {
+	ids := []string{}
+	for _, trigger := range triggers {
+		if inherits(trigger.TemplateID, trigger.Tags) {
+			ids = append(ids, trigger.TemplateID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	parents, err := api.TriggersGet(Params{"triggerids": ids, "output": []string{"triggerid"}, "selectTags": "extend"})
+	if err != nil {
+		return
+	}
+	byID := map[string]Tags{}
+	for _, parent := range parents {
+		byID[parent.TriggerID] = parent.Tags
+	}
+	for i, trigger := range triggers {
+		if tags, ok := byID[trigger.TemplateID]; ok {
+			triggers[i].Tags = trigger.Tags.minus(tags)
+		}
+	}
+	return
+}
+
+// updateTags calls method with objects holding only idField and tags, in chunks
+func (api *API) updateTags(method, idField string, ids []string, tags []Tags) (err error) {
+	start := 0
+	return api.chunked(ids, func(chunk []string) error {
+		objs := make([]Params, 0, len(chunk))
+		for i, id := range chunk {
+			objs = append(objs, Params{idField: id, "tags": tags[start+i]})
+		}
+		start += len(chunk)
+		_, err := api.CallWithError(method, objs)
+		return err
+	})
+}
@@ -13,6 +13,9 @@ type Template struct {
 	ParentTemplates TemplateIDs  `json:"parentTemplates,omitempty"`
 	TemplatesClear  TemplateIDs  `json:"templates_clear,omitempty"`
 	LinkedHosts     []string     `json:"hosts,omitempty"`
+
+	// Depth is the link distance from the host, filled by HostTemplateTree
+	Depth int `json:"-"`
 }
 
 // Templates is an Array of Template structs.
@@ -38,17 +41,78 @@ func (api *API) TemplatesGet(params Params) (res Templates, err error) {
 
 // TemplateGetByID Gets template by Id only if there is exactly 1 matching template.
 func (api *API) TemplateGetByID(id string) (template *Template, err error) {
-	templates, err := api.TemplatesGet(Params{"templateids": id})
+	template = &Template{}
+	if err = api.GetOne("template.get", Params{"templateids": id}, template); err != nil {
+		template = nil
+	}
+	return
+}
+
+// HostTemplateTree Gets every template the host inherits from, directly or through
+// other templates, breadth first. Each template is returned once with the
+// shortest link distance in Depth, 1 for directly linked ones.
+func (api *API) HostTemplateTree(hostID string) (res Templates, err error) {
+	hosts, err := api.HostsGet(Params{
+		"output":                []string{"hostid"},
+		"hostids":               hostID,
+		"selectParentTemplates": []string{"templateid"},
+	})
 	if err != nil {
 		return
 	}
-
-	if len(templates) == 1 {
-		template = &templates[0]
-	} else {
-		e := ExpectedOneResult(len(templates))
+	if len(hosts) != 1 {
+		e := ExpectedOneResult(len(hosts))
 		err = &e
+		return
+	}
+
+	seen := map[string]bool{}
+	level := []string{}
+	for _, t := range hosts[0].ParentTemplateIDs {
+		seen[t.TemplateID] = true
+		level = append(level, t.TemplateID)
+	}
+	for depth := 1; len(level) > 0; depth++ {
+		templates, err := api.TemplatesGet(Params{
+			"templateids":           level,
+			"selectParentTemplates": []string{"templateid"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		level = []string{}
+		for _, t := range templates {
+			t.Depth = depth
+			res = append(res, t)
+			// seen guards against cyclic links
+			for _, parent := range t.ParentTemplates {
+				if !seen[parent.TemplateID] {
+					seen[parent.TemplateID] = true
+					level = append(level, parent.TemplateID)
+				}
+			}
+		}
+	}
+	return
+}
+
+// ResolveTemplateIDs Gets the id of templates by technical name in one call.
+// Resolved ids are returned along with a NotFoundError if some names are unknown.
+func (api *API) ResolveTemplateIDs(names []string) (res map[string]string, err error) {
+	templates, err := api.TemplatesGet(Params{
+		"output": []string{"templateid", "host"},
+		"filter": map[string]interface{}{"host": names},
+	})
+	if err != nil {
+		return
+	}
+
+	res = make(map[string]string, len(templates))
+	for _, t := range templates {
+		res[t.Host] = t.TemplateID
 	}
+	err = checkResolved("templates", names, res)
 	return
 }
 
@@ -1,6 +1,7 @@
 package zabbix_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	zapi "github.com/tpretz/go-zabbix-api"
@@ -64,3 +65,88 @@ func TestTemplates(t *testing.T) {
 
 	DeleteTemplate(template, t)
 }
+
+func TestTemplateUUIDs(t *testing.T) {
+	var sent zapi.Items
+	api := getMockAPI(t, zapi.Config{Version: 60000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "template.get":
+			return []map[string]string{{"templateid": "10", "host": "Template OS"}}, nil
+		case "item.create":
+			json.Unmarshal(params, &sent)
+		}
+		return map[string][]string{"itemids": {"1", "2"}}, nil
+	})
+
+	items := zapi.Items{
+		{HostID: "10", Key: "system.cpu.load", Type: zapi.ZabbixAgent},
+		{HostID: "20", Key: "system.cpu.load", Type: zapi.ZabbixAgent},
+	}
+	if err := api.ItemsCreate(items); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 || len(sent[0].UUID) != 32 || sent[1].UUID != "" {
+		t.Errorf("Expected uuid on template item only: %#v", sent)
+	}
+}
+
+func TestHostTemplateTree(t *testing.T) {
+	// host -> Linux by Zabbix agent -> Linux CPU, with a link back to test the cycle guard
+	parents := map[string][]string{"10001": {"10002"}, "10002": {"10001"}}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method == "host.get" {
+			return []interface{}{map[string]interface{}{
+				"hostid":          "10084",
+				"parentTemplates": []interface{}{map[string]string{"templateid": "10001"}},
+			}}, nil
+		}
+		var p struct {
+			TemplateIDs []string `json:"templateids"`
+		}
+		json.Unmarshal(params, &p)
+		res := []interface{}{}
+		for _, id := range p.TemplateIDs {
+			linked := []interface{}{}
+			for _, parent := range parents[id] {
+				linked = append(linked, map[string]string{"templateid": parent})
+			}
+			res = append(res, map[string]interface{}{"templateid": id, "host": "Template " + id, "parentTemplates": linked})
+		}
+		return res, nil
+	})
+
+	templates, err := api.HostTemplateTree("10084")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 2 {
+		t.Fatalf("Expected 2 templates, got %#v", templates)
+	}
+	if templates[0].TemplateID != "10001" || templates[0].Depth != 1 || templates[1].TemplateID != "10002" || templates[1].Depth != 2 {
+		t.Errorf("Bad template tree: %#v", templates)
+	}
+}
+
+func TestTemplateUUIDCollision(t *testing.T) {
+	calls := 0
+	api := getMockAPI(t, zapi.Config{Version: 60000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method == "template.get" {
+			return []map[string]string{{"templateid": "10", "host": "Template OS"}}, nil
+		}
+		calls++
+		return map[string][]string{"itemids": {"1", "2"}}, nil
+	})
+
+	items := zapi.Items{
+		{HostID: "10", Key: "vfs.fs.size[/,free]", Name: "Free space", Type: zapi.ZabbixAgent},
+		{HostID: "10", Key: "vfs.fs.size[/,free]", Name: "Free space again", Type: zapi.ZabbixAgent},
+	}
+	err := api.ItemsCreate(items)
+	e, ok := err.(*zapi.UUIDCollisionError)
+	if !ok || len(e.Names) != 2 || e.Names[0] != "vfs.fs.size[/,free]" {
+		t.Errorf("Expected UUIDCollisionError, got %#v", err)
+	}
+	if calls != 0 {
+		t.Error("Items sent despite the collision")
+	}
+}
@@ -0,0 +1,83 @@
+package zabbix
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransportConfig(t *testing.T) {
+	api := NewAPI(Config{
+		MaxIdleConns:          5,
+		IdleConnTimeout:       time.Minute,
+		DisableKeepAlives:     true,
+		ResponseHeaderTimeout: 10 * time.Second,
+		TlsNoVerify:           true,
+	})
+
+	tr := api.c.Transport.(*http.Transport)
+	if tr.MaxIdleConns != 5 || tr.IdleConnTimeout != time.Minute || !tr.DisableKeepAlives || tr.ResponseHeaderTimeout != 10*time.Second {
+		t.Errorf("Bad transport: %#v", tr)
+	}
+	if !tr.TLSClientConfig.InsecureSkipVerify {
+		t.Error("Expected insecure TLS")
+	}
+
+	tr = NewAPI(Config{}).c.Transport.(*http.Transport)
+	if tr.MaxIdleConns != defaultMaxIdleConns || tr.IdleConnTimeout != defaultIdleConnTimeout {
+		t.Errorf("Bad default transport: %#v", tr)
+	}
+}
+
+func TestCompressionTransport(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip, deflate, identity" {
+			t.Errorf("Bad Accept-Encoding: %q", r.Header.Get("Accept-Encoding"))
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ = ioutil.ReadAll(zr)
+			requests = append(requests, "gzip")
+		} else {
+			requests = append(requests, "plain")
+		}
+		if !strings.Contains(string(body), "apiinfo.version") {
+			t.Errorf("Bad request body: %s", body)
+		}
+
+		w.Header().Set("Accept-Encoding", "gzip")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(`{"jsonrpc":"2.0","result":"6.0.0","id":1}`))
+		zw.Close()
+	}))
+	defer srv.Close()
+
+	api := NewAPI(Config{Url: srv.URL, EnableCompression: true})
+	for i := 0; i < 2; i++ {
+		response, err := api.CallWithError("apiinfo.version", []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.Result != "6.0.0" {
+			t.Errorf("Bad result: %#v", response.Result)
+		}
+	}
+	if strings.Join(requests, ",") != "plain,gzip" {
+		t.Errorf("Expected the second request only gzip encoded, got %v", requests)
+	}
+
+	if _, ok := NewAPI(Config{}).c.Transport.(*http.Transport); !ok {
+		t.Error("Expected no compression by default")
+	}
+}
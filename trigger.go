@@ -1,9 +1,15 @@
 package zabbix
 
+import "time"
+
 type (
 	// SeverityType of a trigger
 	// Zabbix severity see : https://www.zabbix.com/documentation/3.2/manual/api/reference/trigger/object
 	SeverityType int
+
+	// TriggerType tells whether a trigger generates one or multiple problems
+	// see "type" in https://www.zabbix.com/documentation/6.0/manual/api/reference/trigger/object
+	TriggerType int
 )
 
 const (
@@ -23,6 +29,13 @@ const (
 	Critical SeverityType = 5
 )
 
+const (
+	// TriggerSingleProblem a problem is generated once, when the trigger goes in problem (default)
+	TriggerSingleProblem TriggerType = 0
+	// TriggerMultipleProblems every problem event generates a problem, as used with log items
+	TriggerMultipleProblems TriggerType = 1
+)
+
 const (
 	// Enabled trigger status enabled
 	Enabled StatusType = 0
@@ -35,13 +48,19 @@ const (
 
 	// OK trigger value ok
 	OK ValueType = 0
-	// Problem trigger value probleme
-	Problem ValueType = 1
+	// TriggerProblem trigger value problem
+	TriggerProblem ValueType = 1
+	// Problem trigger value problem
+	//
+	// Deprecated: use TriggerProblem.
+	Problem = TriggerProblem
 )
 
 type Tag struct {
 	Tag   string `json:"tag"`
 	Value string `json:"value,omitempty"`
+	// Automatic is read only, "1" when the host tag is set by discovery
+	Automatic string `json:"automatic,omitempty"`
 }
 
 type Tags []Tag
@@ -68,30 +87,50 @@ type TriggerFunctions []TriggerFunction
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/trigger/object
 type Trigger struct {
 	TriggerID   string `json:"triggerid,omitempty"`
+	UUID        string `json:"uuid,omitempty"`
 	Description string `json:"description"`
 	Expression  string `json:"expression"`
 	Comments    string `json:"comments"`
-	//TemplateId  string    `json:"templateid"`
+	// TemplateID is read only, the template trigger this one is inherited from
+	TemplateID string `json:"templateid,omitempty"`
 	//Value ValueType `json:""`
 
-	Opdata             string `json:"opdata,omitempty"`
-	Type               int    `json:"type,string"`
-	Url                string `json:"url,omitempty"`
-	RecoveryMode       int    `json:"recovery_mode,string"`
-	RecoveryExpression string `json:"recovery_expression,omitempty"`
-	CorrelationMode    int    `json:"correlation_mode,string"`
-	CorrelationTag     string `json:"correlation_tag,omitempty"`
-	ManualClose        int    `json:"manual_close,string"`
+	Opdata             string      `json:"opdata,omitempty"`
+	EventName          string      `json:"event_name,omitempty"`
+	Type               TriggerType `json:"type,string"`
+	Url                string      `json:"url,omitempty"`
+	RecoveryMode       int         `json:"recovery_mode,string"`
+	RecoveryExpression string      `json:"recovery_expression,omitempty"`
+	CorrelationMode    int         `json:"correlation_mode,string"`
+	CorrelationTag     string      `json:"correlation_tag,omitempty"`
+	ManualClose        int         `json:"manual_close,string"`
 
 	Priority     SeverityType     `json:"priority,string"`
 	Status       StatusType       `json:"status,string"`
 	Dependencies TriggerIDs       `json:"dependencies,omitempty"`
 	Functions    TriggerFunctions `json:"functions,omitempty"`
-	// Items contained by the trigger in the items property.
+	// Items contained by the trigger in the items property, read only,
+	// see TriggerGetOptions.SelectItems
 	ContainedItems Items `json:"items,omitempty"`
-	// Hosts that the trigger belongs to in the hosts property.
+	// Hosts that the trigger belongs to in the hosts property, read only.
 	ParentHosts Hosts `json:"hosts,omitempty"`
 	Tags        Tags  `json:"tags,omitempty"`
+
+	// Value and LastChange are read only, the state and the unix time it last changed
+	Value      ValueType `json:"value,omitempty,string"`
+	LastChange string    `json:"lastchange,omitempty"`
+	// ProblemCount is the number of unresolved problems, see TriggerGetOptions.WithProblemCount
+	ProblemCount int `json:"-"`
+}
+
+// LastChangeTime returns when the trigger last changed state, zero if never
+func (t Trigger) LastChangeTime() time.Time {
+	return unixTime(t.LastChange)
+}
+
+// InProblem reports whether the trigger is in the problem state
+func (t Trigger) InProblem() bool {
+	return t.Value == TriggerProblem
 }
 
 // Triggers is an array of Trigger
@@ -106,84 +145,311 @@ func (api *API) TriggersGet(params Params) (res Triggers, err error) {
 	err = api.CallWithErrorParse("trigger.get", params, &res)
 	return
 }
-func (api *API) ProtoTriggersGet(params Params) (res Triggers, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
+
+// TriggerGetOptions typed parameters for trigger.get
+type TriggerGetOptions struct {
+	GetOptions
+	TriggerIDs  []string
+	HostIDs     []string
+	GroupIDs    []string
+	TemplateIDs []string
+	// SelectDependencies fills Dependencies
+	SelectDependencies bool
+	// SelectItems fills ContainedItems with the items the expressions reference
+	SelectItems bool
+	// SelectFunctions fills Functions
+	SelectFunctions bool
+	// WithProblemCount fills ProblemCount with an extra problem.get call
+	WithProblemCount bool
+}
+
+func (o TriggerGetOptions) params() Params {
+	params := o.GetOptions.params()
+	params.setIDs("triggerids", o.TriggerIDs)
+	params.setIDs("hostids", o.HostIDs)
+	params.setIDs("groupids", o.GroupIDs)
+	params.setIDs("templateids", o.TemplateIDs)
+	if o.SelectDependencies {
+		params["selectDependencies"] = []string{"triggerid"}
 	}
-	err = api.CallWithErrorParse("triggerprototype.get", params, &res)
-	return
+	if o.SelectItems {
+		params["selectItems"] = "extend"
+	}
+	if o.SelectFunctions {
+		params["selectFunctions"] = "extend"
+	}
+	return params
 }
 
-// TriggerGetByID Gets trigger by Id only if there is exactly 1 matching host.
-func (api *API) TriggerGetByID(id string) (res *Trigger, err error) {
-	triggers, err := api.TriggersGet(Params{"triggerids": id})
+// TriggersGetWithOptions Wrapper for trigger.get using typed options
+func (api *API) TriggersGetWithOptions(opts TriggerGetOptions) (res Triggers, err error) {
+	defer func() {
+		if err == nil {
+			err = opts.truncated(len(res))
+		}
+	}()
+	res, err = api.TriggersGet(opts.params())
+	if err != nil || !opts.WithProblemCount || len(res) == 0 {
+		return
+	}
+
+	ids := make([]string, len(res))
+	for i, t := range res {
+		ids[i] = t.TriggerID
+	}
+	var problems Problems
+	err = api.CallWithErrorParse("problem.get", Params{
+		"output":    []string{"eventid", "objectid"},
+		"source":    EventSourceTrigger,
+		"object":    0,
+		"objectids": ids,
+	}, &problems)
 	if err != nil {
 		return
 	}
+	count := map[string]int{}
+	for _, p := range problems {
+		count[p.ObjectID]++
+	}
+	for i, t := range res {
+		res[i].ProblemCount = count[t.TriggerID]
+	}
+	return
+}
 
+// TriggerReferencedItems Gets the items referenced by the expressions of the trigger.
+func (api *API) TriggerReferencedItems(triggerID string) (res Items, err error) {
+	triggers, err := api.TriggersGetWithOptions(TriggerGetOptions{
+		TriggerIDs:  []string{triggerID},
+		SelectItems: true,
+	})
+	if err != nil {
+		return
+	}
 	if len(triggers) != 1 {
 		e := ExpectedOneResult(len(triggers))
-		err = &e
-		return
+		return nil, &e
+	}
+	return triggers[0].ContainedItems, nil
+}
+
+// TriggerDependencyGraph Gets the trigger and all triggers it depends on, directly or not.
+// The requested trigger comes first, followed by its dependencies breadth first.
+func (api *API) TriggerDependencyGraph(triggerID string) (res Triggers, err error) {
+	seen := map[string]bool{triggerID: true}
+	next := []string{triggerID}
+	for len(next) > 0 {
+		triggers, err := api.TriggersGetWithOptions(TriggerGetOptions{
+			TriggerIDs:         next,
+			SelectDependencies: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		next = nil
+		for _, trigger := range triggers {
+			res = append(res, trigger)
+			for _, dep := range trigger.Dependencies {
+				if !seen[dep.TriggerID] {
+					seen[dep.TriggerID] = true
+					next = append(next, dep.TriggerID)
+				}
+			}
+		}
 	}
-	res = &triggers[0]
 	return
 }
-func (api *API) ProtoTriggerGetByID(id string) (res *Trigger, err error) {
-	triggers, err := api.ProtoTriggersGet(Params{"triggerids": id})
+
+// TriggersSetStatus Enables or disables the triggers, updating only their status.
+func (api *API) TriggersSetStatus(triggerIDs []string, enabled bool) (err error) {
+	status := Disabled
+	if enabled {
+		status = Enabled
+	}
+	return api.chunked(triggerIDs, func(chunk []string) error {
+		objs := make([]Params, 0, len(chunk))
+		for _, id := range chunk {
+			objs = append(objs, Params{"triggerid": id, "status": status})
+		}
+		_, err := api.CallWithError("trigger.update", objs)
+		return err
+	})
+}
+
+// TriggersSetStatusCascade Same as TriggersSetStatus, also applied to the triggers
+// depending on them, directly or not. Only the triggers of the same hosts are cascaded to.
+func (api *API) TriggersSetStatusCascade(triggerIDs []string, enabled bool) (err error) {
+	ids, err := api.triggerDependents(triggerIDs)
 	if err != nil {
 		return
 	}
+	return api.TriggersSetStatus(ids, enabled)
+}
 
-	if len(triggers) != 1 {
-		e := ExpectedOneResult(len(triggers))
-		err = &e
+// triggerDependents returns triggerIDs followed by the triggers of their hosts depending
+// on them, breadth first
+func (api *API) triggerDependents(triggerIDs []string) (res []string, err error) {
+	triggers, err := api.TriggersGet(Params{
+		"output":      []string{"triggerid"},
+		"triggerids":  triggerIDs,
+		"selectHosts": []string{"hostid"},
+	})
+	if err != nil {
 		return
 	}
-	res = &triggers[0]
+	hostIDs := []string{}
+	seenHosts := map[string]bool{}
+	for _, t := range triggers {
+		for _, h := range t.ParentHosts {
+			if !seenHosts[h.HostID] {
+				seenHosts[h.HostID] = true
+				hostIDs = append(hostIDs, h.HostID)
+			}
+		}
+	}
+
+	// trigger.get can only select what a trigger depends on, build the reverse map
+	triggers = nil
+	if len(hostIDs) > 0 {
+		triggers, err = api.TriggersGet(Params{
+			"output":             []string{"triggerid"},
+			"hostids":            hostIDs,
+			"dependent":          true,
+			"selectDependencies": []string{"triggerid"},
+		})
+		if err != nil {
+			return
+		}
+	}
+	dependents := map[string][]string{}
+	for _, t := range triggers {
+		for _, dep := range t.Dependencies {
+			dependents[dep.TriggerID] = append(dependents[dep.TriggerID], t.TriggerID)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, id := range triggerIDs {
+		if !seen[id] {
+			seen[id] = true
+			res = append(res, id)
+		}
+	}
+	for i := 0; i < len(res); i++ {
+		for _, id := range dependents[res[i]] {
+			if !seen[id] {
+				seen[id] = true
+				res = append(res, id)
+			}
+		}
+	}
 	return
 }
 
+func (api *API) ProtoTriggersGet(params Params) (res Triggers, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("triggerprototype.get", params, &res)
+	return
+}
+
+// TriggerGetByID Gets trigger by Id only if there is exactly 1 matching host.
+func (api *API) TriggerGetByID(id string) (res *Trigger, err error) {
+	res = &Trigger{}
+	if err = api.GetOne("trigger.get", Params{"triggerids": id}, res); err != nil {
+		res = nil
+	}
+	return
+}
+func (api *API) ProtoTriggerGetByID(id string) (res *Trigger, err error) {
+	res = &Trigger{}
+	if err = api.GetOne("triggerprototype.get", Params{"triggerids": id}, res); err != nil {
+		res = nil
+	}
+	return
+}
+
+func prepTriggers(triggers Triggers) {
+	for i := range triggers {
+		// read only
+		triggers[i].Value, triggers[i].LastChange = OK, ""
+		triggers[i].Functions, triggers[i].ContainedItems, triggers[i].ParentHosts = nil, nil, nil
+		triggers[i].TemplateID = ""
+	}
+}
+
 // TriggersCreate Wrapper for trigger.create
+// Template triggers missing an UUID get one on Zabbix 6.0+.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/trigger/create
 func (api *API) TriggersCreate(triggers Triggers) (err error) {
+	if err = api.fillTriggerUUIDs(triggers); err != nil {
+		return
+	}
+	prepTriggers(triggers)
 	response, err := api.CallWithError("trigger.create", triggers)
 	if err != nil {
 		return
 	}
 
-	result := response.Result.(map[string]interface{})
-	triggerids := result["triggerids"].([]interface{})
+	triggerids, err := resultIDs(response, "triggerids")
+	if err != nil {
+		return
+	}
+	if len(triggerids) != len(triggers) {
+		return &ExpectedMore{len(triggers), len(triggerids)}
+	}
 	for i, id := range triggerids {
-		triggers[i].TriggerID = id.(string)
+		triggers[i].TriggerID = id
 	}
 	return
 }
 func (api *API) ProtoTriggersCreate(triggers Triggers) (err error) {
+	if err = api.fillTriggerUUIDs(triggers); err != nil {
+		return
+	}
+	prepTriggers(triggers)
 	response, err := api.CallWithError("triggerprototype.create", triggers)
 	if err != nil {
 		return
 	}
 
-	result := response.Result.(map[string]interface{})
-	triggerids := result["triggerids"].([]interface{})
+	triggerids, err := resultIDs(response, "triggerids")
+	if err != nil {
+		return
+	}
+	if len(triggerids) != len(triggers) {
+		return &ExpectedMore{len(triggers), len(triggerids)}
+	}
 	for i, id := range triggerids {
-		triggers[i].TriggerID = id.(string)
+		triggers[i].TriggerID = id
 	}
 	return
 }
 
 // TriggersUpdate Wrapper for trigger.update
+// Tags templated triggers share with their template trigger are not sent.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/trigger/update
 func (api *API) TriggersUpdate(triggers Triggers) (err error) {
+	if err = api.stripInheritedTriggerTags(triggers); err != nil {
+		return
+	}
+	prepTriggers(triggers)
 	_, err = api.CallWithError("trigger.update", triggers)
 	return
 }
 func (api *API) ProtoTriggersUpdate(triggers Triggers) (err error) {
+	prepTriggers(triggers)
 	_, err = api.CallWithError("triggerprototype.update", triggers)
 	return
 }
 
+// UpdateTriggerFields Wrapper for trigger.update sending only the given fields and the trigger id.
+func (api *API) UpdateTriggerFields(triggerID string, fields map[string]interface{}) (err error) {
+	return api.updateFields("trigger.update", "triggerid", triggerID, fields)
+}
+
 // TriggersDelete Wrapper for trigger.delete
 // Cleans ItemId in all triggers elements if call succeed.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/trigger/delete
@@ -249,15 +515,9 @@ func (api *API) TriggersDeleteIDs(ids []string) (triggerids []interface{}, err e
 		return
 	}
 
-	result := response.Result.(map[string]interface{})
-	triggerids1, ok := result["triggerids"].([]interface{})
-	if !ok {
-		triggerids2 := result["triggerids"].(map[string]interface{})
-		for _, id := range triggerids2 {
-			triggerids = append(triggerids, id)
-		}
-	} else {
-		triggerids = triggerids1
+	deleted, err := resultIDs(response, "triggerids")
+	for _, id := range deleted {
+		triggerids = append(triggerids, id)
 	}
 	return
 }
@@ -267,15 +527,38 @@ func (api *API) ProtoTriggersDeleteIDs(ids []string) (triggerids []interface{},
 		return
 	}
 
-	result := response.Result.(map[string]interface{})
-	triggerids1, ok := result["triggerids"].([]interface{})
-	if !ok {
-		triggerids2 := result["triggerids"].(map[string]interface{})
-		for _, id := range triggerids2 {
-			triggerids = append(triggerids, id)
-		}
-	} else {
-		triggerids = triggerids1
+	deleted, err := resultIDs(response, "triggerids")
+	for _, id := range deleted {
+		triggerids = append(triggerids, id)
 	}
 	return
 }
+
+// TriggersAddTag Adds tag to the triggers, keeping their existing tags.
+func (api *API) TriggersAddTag(triggerIDs []string, tag, value string) (err error) {
+	return api.triggersEditTags(triggerIDs, func(tags Tags) Tags { return tags.with(tag, value) })
+}
+
+// TriggersRemoveTag Removes tag from the triggers, any value matches if value is empty.
+func (api *API) TriggersRemoveTag(triggerIDs []string, tag, value string) (err error) {
+	return api.triggersEditTags(triggerIDs, func(tags Tags) Tags { return tags.without(tag, value) })
+}
+
+func (api *API) triggersEditTags(triggerIDs []string, edit func(Tags) Tags) (err error) {
+	triggers, err := api.TriggersGet(Params{
+		"triggerids": triggerIDs,
+		"output":     []string{"triggerid"},
+		"selectTags": "extend",
+	})
+	if err != nil {
+		return
+	}
+
+	ids := make([]string, len(triggers))
+	tags := make([]Tags, len(triggers))
+	for i, trigger := range triggers {
+		ids[i] = trigger.TriggerID
+		tags[i] = edit(trigger.Tags)
+	}
+	return api.updateTags("trigger.update", "triggerid", ids, tags)
+}
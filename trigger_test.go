@@ -1,6 +1,7 @@
 package zabbix_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -61,3 +62,245 @@ func TestTrigger(t *testing.T) {
 
 	DeleteTrigger(trigger, t)
 }
+
+func TestTriggerDependencyGraph(t *testing.T) {
+	deps := map[string][]string{"1": {"2", "3"}, "2": {"3"}, "3": nil}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var p struct {
+			TriggerIDs []string `json:"triggerids"`
+		}
+		json.Unmarshal(params, &p)
+
+		res := []map[string]interface{}{}
+		for _, id := range p.TriggerIDs {
+			ids := []map[string]string{}
+			for _, dep := range deps[id] {
+				ids = append(ids, map[string]string{"triggerid": dep})
+			}
+			res = append(res, map[string]interface{}{"triggerid": id, "dependencies": ids})
+		}
+		return res, nil
+	})
+
+	triggers, err := api.TriggerDependencyGraph("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(triggers) != 3 || len(triggers[0].Dependencies) != 2 {
+		t.Errorf("Bad dependency graph: %#v", triggers)
+	}
+}
+
+func TestTriggersGetWithProblemCount(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method == "problem.get" {
+			return []map[string]string{
+				{"eventid": "1", "objectid": "13"},
+				{"eventid": "2", "objectid": "13"},
+				{"eventid": "3", "objectid": "14"},
+			}, nil
+		}
+		return []map[string]string{
+			{"triggerid": "13", "description": "Disk full", "lastchange": "1700000000"},
+			{"triggerid": "14", "description": "High CPU load", "lastchange": "1700000600"},
+			{"triggerid": "15", "description": "Host down", "lastchange": "0"},
+		}, nil
+	})
+
+	triggers, err := api.TriggersGetWithOptions(zapi.TriggerGetOptions{HostIDs: []string{"10084"}, WithProblemCount: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(triggers) != 3 {
+		t.Fatalf("Bad triggers: %#v", triggers)
+	}
+	for i, expected := range []int{2, 1, 0} {
+		if triggers[i].ProblemCount != expected {
+			t.Errorf("Trigger %s: expected %d problems, got %d", triggers[i].TriggerID, expected, triggers[i].ProblemCount)
+		}
+	}
+	if triggers[0].LastChangeTime().Unix() != 1700000000 || !triggers[2].LastChangeTime().IsZero() {
+		t.Errorf("Bad last change: %s %s", triggers[0].LastChangeTime(), triggers[2].LastChangeTime())
+	}
+}
+
+func TestTriggersSetStatus(t *testing.T) {
+	// 2 depends on 1, 3 on 2, 4 on nothing updated
+	deps := []map[string]interface{}{
+		{"triggerid": "2", "dependencies": []map[string]string{{"triggerid": "1"}}},
+		{"triggerid": "3", "dependencies": []map[string]string{{"triggerid": "2"}}},
+		{"triggerid": "5", "dependencies": []map[string]string{{"triggerid": "4"}}},
+	}
+	var updated []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "trigger.get":
+			var p map[string]interface{}
+			json.Unmarshal(params, &p)
+			if p["triggerids"] != nil {
+				return []map[string]interface{}{{"triggerid": "1", "hosts": []map[string]string{{"hostid": "10084"}}}}, nil
+			}
+			if fmt.Sprint(p["hostids"]) != "[10084]" || p["dependent"] != true {
+				t.Errorf("Dependents not scoped to the hosts: %s", params)
+			}
+			return deps, nil
+		case "trigger.update":
+			var objs []map[string]interface{}
+			json.Unmarshal(params, &objs)
+			updated = append(updated, objs...)
+			return map[string][]string{"triggerids": {}}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+
+	if err := api.TriggersSetStatus([]string{"1"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(updated) != 1 || len(updated[0]) != 2 || updated[0]["triggerid"] != "1" || updated[0]["status"] != float64(zapi.Disabled) {
+		t.Errorf("Bad update: %#v", updated)
+	}
+
+	updated = nil
+	if err := api.TriggersSetStatusCascade([]string{"1"}, true); err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{}
+	for _, u := range updated {
+		if u["status"] != float64(zapi.Enabled) {
+			t.Errorf("Bad status: %#v", u)
+		}
+		ids = append(ids, u["triggerid"].(string))
+	}
+	if fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("Bad cascade: %v", ids)
+	}
+}
+
+func TestPreviewEventName(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		return []map[string]interface{}{{
+			"triggerid":   "13",
+			"description": "High CPU load",
+			"event_name":  "High CPU on {HOST.NAME}: {ITEM.VALUE} ({ITEM.KEY2} {ITEM.VALUE2}) {TRIGGER.SEVERITY} {ITEM.NAME3} {$THRESHOLD}",
+			"expression":  "{100}>{$THRESHOLD} and {101}<10",
+			"priority":    "4",
+			"functions": []map[string]string{
+				{"functionid": "101", "itemid": "23297"},
+				{"functionid": "100", "itemid": "23296"},
+			},
+			"items": []map[string]string{
+				{"itemid": "23297", "hostid": "10084", "name": "Free memory", "key_": "vm.memory.size[pavailable]", "lastvalue": "5", "units": "%"},
+				{"itemid": "23296", "hostid": "10084", "name": "CPU load", "key_": "system.cpu.load", "lastvalue": "3.2"},
+			},
+			"hosts": []map[string]string{{"hostid": "10084", "host": "web01", "name": "Web server 1"}},
+		}}, nil
+	})
+
+	name, err := api.PreviewEventName("13")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "High CPU on Web server 1: 3.2 (vm.memory.size[pavailable] 5 %) High *UNKNOWN* {$THRESHOLD}"
+	if name != expected {
+		t.Errorf("Expected %q, got %q", expected, name)
+	}
+}
+
+func TestTriggersCreateMultipleProblems(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{Version: 50000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		if method != "trigger.create" {
+			t.Fatalf("Unexpected method %s", method)
+		}
+		json.Unmarshal(params, &sent)
+		return map[string][]string{"triggerids": {"7"}}, nil
+	})
+
+	triggers := zapi.Triggers{{
+		Description: "Error in log",
+		Expression:  `find(/host/log[/var/log/app.log],,"like","ERROR")=1`,
+		Type:        zapi.TriggerMultipleProblems,
+		ManualClose: 1,
+	}}
+	if err := api.TriggersCreate(triggers); err != nil {
+		t.Fatal(err)
+	}
+	if triggers[0].TriggerID != "7" || len(sent) != 1 || sent[0]["type"] != "1" {
+		t.Errorf("Bad trigger sent: %#v", sent)
+	}
+}
+
+func TestTriggerReferencedItems(t *testing.T) {
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		var p map[string]interface{}
+		json.Unmarshal(params, &p)
+		if method != "trigger.get" || p["selectItems"] != "extend" {
+			t.Fatalf("Unexpected call %s %s", method, params)
+		}
+		return []interface{}{map[string]interface{}{
+			"triggerid":   "13",
+			"description": "Low free space",
+			"expression":  "last(/Linux/vfs.fs.size[/,pfree])<10 and last(/Linux/vfs.fs.size[/,free])<1G",
+			"functions": []map[string]string{
+				{"functionid": "30", "itemid": "100", "triggerid": "13", "function": "last", "parameter": "$"},
+				{"functionid": "31", "itemid": "101", "triggerid": "13", "function": "last", "parameter": "$"},
+			},
+			"items": []map[string]string{
+				{"itemid": "100", "hostid": "10084", "key_": "vfs.fs.size[/,pfree]", "value_type": "0", "type": "0"},
+				{"itemid": "101", "hostid": "10084", "key_": "vfs.fs.size[/,free]", "value_type": "3", "type": "0"},
+			},
+		}}, nil
+	})
+
+	triggers, err := api.TriggersGetWithOptions(zapi.TriggerGetOptions{TriggerIDs: []string{"13"}, SelectItems: true, SelectFunctions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(triggers) != 1 || len(triggers[0].Functions) != 2 || triggers[0].Functions[1].ItemID != "101" || triggers[0].Functions[0].Function != "last" {
+		t.Fatalf("Bad trigger functions: %#v", triggers)
+	}
+
+	items, err := api.TriggerReferencedItems("13")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Key != "vfs.fs.size[/,pfree]" || items[1].ItemID != "101" || items[1].ValueType != zapi.Unsigned {
+		t.Errorf("Bad referenced items: %#v", items)
+	}
+}
+
+func TestTriggersUpdateStripsInheritedTags(t *testing.T) {
+	var sent []map[string]interface{}
+	api := getMockAPI(t, zapi.Config{}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "trigger.get":
+			return []interface{}{map[string]interface{}{
+				"triggerid": "13001",
+				"tags":      []interface{}{map[string]string{"tag": "scope", "value": "availability"}},
+			}}, nil
+		case "trigger.update":
+			json.Unmarshal(params, &sent)
+			return map[string][]string{"triggerids": {"13500"}}, nil
+		}
+		t.Fatalf("Unexpected call %s", method)
+		return nil, nil
+	})
+
+	triggers := zapi.Triggers{
+		{TriggerID: "13500", TemplateID: "13001", Tags: zapi.Tags{{Tag: "scope", Value: "availability"}, {Tag: "team", Value: "ops"}}},
+		{TriggerID: "13501", TemplateID: "0", Tags: zapi.Tags{{Tag: "scope", Value: "availability"}}},
+	}
+	if err := api.TriggersUpdate(triggers); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("Bad triggers sent: %#v", sent)
+	}
+	if tags := sent[0]["tags"].([]interface{}); len(tags) != 1 || tags[0].(map[string]interface{})["tag"] != "team" {
+		t.Errorf("Bad inherited trigger tags: %#v", sent[0])
+	}
+	if tags := sent[1]["tags"].([]interface{}); len(tags) != 1 || tags[0].(map[string]interface{})["tag"] != "scope" {
+		t.Errorf("Bad local trigger tags: %#v", sent[1])
+	}
+}
@@ -0,0 +1,165 @@
+package zabbix
+
+type (
+	// UserType type of a user role
+	// see "type" in https://www.zabbix.com/documentation/6.0/manual/api/reference/role/object
+	UserType int
+)
+
+const (
+	// UserTypeUser user (default)
+	UserTypeUser UserType = 1
+	// UserTypeAdmin admin
+	UserTypeAdmin UserType = 2
+	// UserTypeSuperAdmin super admin
+	UserTypeSuperAdmin UserType = 3
+)
+
+// RoleRule is a named rule of a role, like an UI element or an action
+type RoleRule struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// RoleRules represent Zabbix role rules object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/role/object#role-rules
+type RoleRules struct {
+	UI                   []RoleRule `json:"ui,omitempty"`
+	UIDefaultAccess      string     `json:"ui.default_access,omitempty"`
+	APIAccess            string     `json:"api.access,omitempty"`
+	APIMode              string     `json:"api.mode,omitempty"`
+	API                  []string   `json:"api,omitempty"`
+	Actions              []RoleRule `json:"actions,omitempty"`
+	ActionsDefaultAccess string     `json:"actions.default_access,omitempty"`
+}
+
+// Role represent Zabbix user role object, Zabbix 5.2+
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/role/object
+type Role struct {
+	RoleID   string     `json:"roleid,omitempty"`
+	Name     string     `json:"name"`
+	Type     UserType   `json:"type,string"`
+	ReadOnly string     `json:"readonly,omitempty"`
+	Rules    *RoleRules `json:"rules,omitempty"`
+}
+
+// UsrGroup represent Zabbix user group object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/usergroup/object
+type UsrGroup struct {
+	UsrGrpID    string `json:"usrgrpid,omitempty"`
+	Name        string `json:"name"`
+	GuiAccess   string `json:"gui_access,omitempty"`
+	UsersStatus string `json:"users_status,omitempty"`
+	DebugMode   string `json:"debug_mode,omitempty"`
+}
+
+// User represent Zabbix user object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/user/object
+type User struct {
+	UserID   string `json:"userid,omitempty"`
+	Username string `json:"username,omitempty"`
+	// Alias is the username before Zabbix 5.4
+	Alias   string `json:"alias,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Surname string `json:"surname,omitempty"`
+	RoleID  string `json:"roleid,omitempty"`
+
+	// Role and UsrGroups are read only, filled when selected
+	Role      *Role      `json:"role,omitempty"`
+	UsrGroups []UsrGroup `json:"usrgrps,omitempty"`
+}
+
+// Users is an array of User
+type Users []User
+
+// UsersGet Wrapper for user.get
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/user/get
+func (api *API) UsersGet(params Params) (res Users, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("user.get", params, &res)
+	return
+}
+
+// checkAuthentication calls "user.checkAuthentication" for token, sent as a token
+// on Zabbix 7.0+ and as a session id before
+func (api *API) checkAuthentication(token string) (user User, err error) {
+	v, err := api.ServerVersion()
+	if err != nil {
+		return
+	}
+	params := Params{"sessionid": token}
+	if v >= 70000 {
+		params = Params{"token": token}
+	}
+	err = api.callWithErrorParseAuth("user.checkAuthentication", params, &user, "")
+	return
+}
+
+// currentUserID returns the id of the user behind api.Auth, from the
+// session filled by LoginDetailed or asking "user.checkAuthentication".
+func (api *API) currentUserID() (id string, err error) {
+	if api.Session != nil && api.Session.Token == api.Auth {
+		return api.Session.UserID, nil
+	}
+	user, err := api.checkAuthentication(api.Auth)
+	return user.UserID, err
+}
+
+// WhoAmI Gets the user behind api.Auth, a session id or an API token on Zabbix 7.0+,
+// with its user groups and, on Zabbix 5.2+, its role.
+func (api *API) WhoAmI() (res *User, err error) {
+	id, err := api.currentUserID()
+	if err != nil {
+		return
+	}
+	params := Params{"userids": id, "selectUsrgrps": "extend"}
+	if v, _ := api.ServerVersion(); v >= 50200 {
+		params["selectRole"] = "extend"
+	}
+	users, err := api.UsersGet(params)
+	if err != nil {
+		return
+	}
+	if len(users) != 1 {
+		e := ExpectedOneResult(len(users))
+		return nil, &e
+	}
+	return &users[0], nil
+}
+
+// CheckAuthentication Calls "user.checkAuthentication" to tell whether token, a session id
+// or an API token on Zabbix 7.0+, is still valid. An expired or unknown token is not an error.
+// api.Auth is neither used nor modified.
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/user/checkauthentication
+func (api *API) CheckAuthentication(token string) (valid bool, err error) {
+	_, err = api.checkAuthentication(token)
+	if e, ok := err.(*Error); ok && e.isAuthError() {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CurrentUserPermissions Gets the role and groups of the logged in user, Zabbix 5.2+
+func (api *API) CurrentUserPermissions() (role *Role, groups []UsrGroup, err error) {
+	id, err := api.currentUserID()
+	if err != nil {
+		return
+	}
+	users, err := api.UsersGet(Params{
+		"output":        []string{"userid"},
+		"userids":       id,
+		"selectRole":    "extend",
+		"selectUsrgrps": "extend",
+	})
+	if err != nil {
+		return
+	}
+	if len(users) != 1 {
+		e := ExpectedOneResult(len(users))
+		err = &e
+		return
+	}
+	return users[0].Role, users[0].UsrGroups, nil
+}
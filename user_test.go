@@ -0,0 +1,126 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestCurrentUserPermissions(t *testing.T) {
+	methods := []string{}
+	api := getMockAPI(t, zapi.Config{Version: 60000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		methods = append(methods, method)
+		if method == "user.checkAuthentication" {
+			return map[string]string{"userid": "3", "username": "automation", "sessionid": "token"}, nil
+		}
+		return []interface{}{map[string]interface{}{
+			"userid": "3",
+			"role": map[string]interface{}{
+				"roleid":   "2",
+				"name":     "Admin role",
+				"type":     "2",
+				"readonly": "0",
+				"rules":    map[string]interface{}{"api.access": "1", "api.mode": "0", "api": []string{"*.delete"}},
+			},
+			"usrgrps": []map[string]string{{"usrgrpid": "7", "name": "Zabbix administrators"}},
+		}}, nil
+	})
+	api.Auth = "token"
+
+	role, groups, err := api.CurrentUserPermissions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(methods) != 2 || methods[0] != "user.checkAuthentication" {
+		t.Errorf("Bad calls: %v", methods)
+	}
+	if role == nil || role.Type != zapi.UserTypeAdmin || role.Rules == nil || role.Rules.APIMode != "0" || len(role.Rules.API) != 1 {
+		t.Errorf("Bad role: %#v", role)
+	}
+	if len(groups) != 1 || groups[0].Name != "Zabbix administrators" {
+		t.Errorf("Bad groups: %#v", groups)
+	}
+}
+
+func TestCheckAuthentication(t *testing.T) {
+	for _, version := range []int{60000, 70000} {
+		var sent map[string]string
+		api := getMockAPI(t, zapi.Config{Version: version}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+			sent = nil
+			json.Unmarshal(params, &sent)
+			for _, token := range sent {
+				if token != "valid" {
+					return nil, &zapi.Error{Code: -32500, Message: "Application error.", Data: "Session terminated, re-login, please."}
+				}
+			}
+			return map[string]string{"userid": "1", "sessionid": "valid"}, nil
+		})
+		api.Auth = "current"
+
+		key := "sessionid"
+		if version >= 70000 {
+			key = "token"
+		}
+		valid, err := api.CheckAuthentication("valid")
+		if err != nil || !valid || len(sent) != 1 || sent[key] != "valid" {
+			t.Errorf("%d: expected valid token sent as %s, got %v, %v, %v", version, key, valid, err, sent)
+		}
+		if valid, err = api.CheckAuthentication("expired"); err != nil || valid {
+			t.Errorf("%d: expected invalid token, got %v, %v", version, valid, err)
+		}
+		if api.Auth != "current" {
+			t.Errorf("Auth modified: %q", api.Auth)
+		}
+	}
+}
+
+func TestWhoAmI(t *testing.T) {
+	var checked []map[string]string
+	api := getMockAPI(t, zapi.Config{Version: 70000}, func(method string, params json.RawMessage) (interface{}, *zapi.Error) {
+		switch method {
+		case "user.checkAuthentication":
+			var p map[string]string
+			json.Unmarshal(params, &p)
+			checked = append(checked, p)
+			if p["token"] != "apitoken" {
+				return nil, &zapi.Error{Code: -32500, Message: "Application error.", Data: "Session terminated, re-login, please."}
+			}
+			return map[string]string{"userid": "3"}, nil
+		case "user.get":
+			var p map[string]interface{}
+			json.Unmarshal(params, &p)
+			if p["userids"] != "3" || p["selectRole"] != "extend" {
+				t.Errorf("Bad user.get params: %s", params)
+			}
+			return []interface{}{map[string]interface{}{
+				"userid":   "3",
+				"username": "automation",
+				"roleid":   "2",
+				"role":     map[string]string{"roleid": "2", "name": "Admin role"},
+				"usrgrps":  []map[string]string{{"usrgrpid": "7", "name": "Zabbix administrators"}},
+			}}, nil
+		}
+		t.Fatalf("Unexpected method %s", method)
+		return nil, nil
+	})
+	api.Auth = "apitoken"
+
+	user, err := api.WhoAmI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "automation" || user.Role == nil || user.Role.Name != "Admin role" || len(user.UsrGroups) != 1 {
+		t.Errorf("Bad user: %#v", user)
+	}
+	if len(checked) != 1 || checked[0]["token"] != "apitoken" {
+		t.Errorf("Bad authentication checks: %v", checked)
+	}
+
+	checked = nil
+	api.Auth = "session"
+	api.Session = &zapi.Session{Token: "session", UserID: "3"}
+	if user, err = api.WhoAmI(); err != nil || user.UserID != "3" || len(checked) != 0 {
+		t.Errorf("Session user not used: %#v %v %v", user, err, checked)
+	}
+}
@@ -0,0 +1,245 @@
+package zabbix
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// UUIDCollisionError is returned when objects of a create batch end up with the same uuid,
+// like template items generated from identical keys
+type UUIDCollisionError struct {
+	Object string
+	Names  []string
+}
+
+func (e *UUIDCollisionError) Error() string {
+	return fmt.Sprintf("Duplicate uuid for %s %s.", e.Object, strings.Join(e.Names, ", "))
+}
+
+// checkUUIDs returns a UUIDCollisionError listing the names sharing an uuid,
+// uuids and names are parallel and empty uuids are ignored
+func checkUUIDs(object string, uuids, names []string) error {
+	first := map[string]int{}
+	colliding := map[int]bool{}
+	for i, uuid := range uuids {
+		if uuid == "" {
+			continue
+		}
+		if j, ok := first[uuid]; ok {
+			colliding[j] = true
+			colliding[i] = true
+			continue
+		}
+		first[uuid] = i
+	}
+	if len(colliding) == 0 {
+		return nil
+	}
+
+	res := []string{}
+	for i, name := range names {
+		if colliding[i] {
+			res = append(res, name)
+		}
+	}
+	return &UUIDCollisionError{object, res}
+}
+
+// templateUUID generates the uuid Zabbix derives from seed parts when converting
+// templates for 6.0: a version 4 uuid built from the md5 of the seed.
+func templateUUID(seed ...string) string {
+	sum := md5.Sum([]byte(strings.Join(seed, "/")))
+	sum[6] = sum[6]&0x0f | 0x40
+	sum[8] = sum[8]&0x3f | 0x80
+	return hex.EncodeToString(sum[:])
+}
+
+// templateUUIDs reports whether the server expects uuids on template objects
+func (api *API) templateUUIDs() bool {
+	v, err := api.ServerVersion()
+	return err == nil && v >= 60000
+}
+
+// templateNames returns the technical name of the templates among ids
+func (api *API) templateNames(ids []string) (res map[string]string, err error) {
+	res = map[string]string{}
+	if len(ids) == 0 {
+		return
+	}
+	templates, err := api.TemplatesGet(Params{
+		"templateids": ids,
+		"output":      []string{"templateid", "host"},
+	})
+	for _, t := range templates {
+		res[t.TemplateID] = t.Host
+	}
+	return
+}
+
+// fillItemUUIDs sets the uuid of template items missing one
+func (api *API) fillItemUUIDs(items Items) (err error) {
+	if !api.templateUUIDs() {
+		return
+	}
+	ids := []string{}
+	for _, item := range items {
+		if item.UUID == "" {
+			ids = append(ids, item.HostID)
+		}
+	}
+	names, err := api.templateNames(ids)
+	if err != nil {
+		return
+	}
+	uuids, keys := make([]string, len(items)), make([]string, len(items))
+	for i, item := range items {
+		if name, ok := names[item.HostID]; ok && item.UUID == "" {
+			items[i].UUID = templateUUID(name, item.Key)
+		}
+		uuids[i], keys[i] = items[i].UUID, item.Key
+	}
+	return checkUUIDs("items", uuids, keys)
+}
+
+// fillLLDUUIDs sets the uuid of template discovery rules missing one
+func (api *API) fillLLDUUIDs(rules LLDRules) (err error) {
+	if !api.templateUUIDs() {
+		return
+	}
+	ids := []string{}
+	for _, rule := range rules {
+		if rule.UUID == "" {
+			ids = append(ids, rule.HostID)
+		}
+	}
+	names, err := api.templateNames(ids)
+	if err != nil {
+		return
+	}
+	uuids, keys := make([]string, len(rules)), make([]string, len(rules))
+	for i, rule := range rules {
+		if name, ok := names[rule.HostID]; ok && rule.UUID == "" {
+			rules[i].UUID = templateUUID(name, rule.Key)
+		}
+		uuids[i], keys[i] = rules[i].UUID, rule.Key
+	}
+	return checkUUIDs("discovery rules", uuids, keys)
+}
+
+// expressionHosts matches the host of "/host/key" references in 6.0 trigger expressions
+var expressionHosts = regexp.MustCompile(`\(/([^/]+)/`)
+
+// fillTriggerUUIDs sets the uuid of template triggers missing one,
+// a trigger belongs to a template when its expression references one
+func (api *API) fillTriggerUUIDs(triggers Triggers) (err error) {
+	if !api.templateUUIDs() {
+		return
+	}
+	hosts := []string{}
+	for _, trigger := range triggers {
+		if trigger.UUID != "" {
+			continue
+		}
+		for _, m := range expressionHosts.FindAllStringSubmatch(trigger.Expression, -1) {
+			hosts = append(hosts, m[1])
+		}
+	}
+	if len(hosts) == 0 {
+		return
+	}
+
+	templates, err := api.TemplatesGet(Params{
+		"filter": map[string]interface{}{"host": hosts},
+		"output": []string{"templateid", "host"},
+	})
+	if err != nil {
+		return
+	}
+	isTemplate := map[string]bool{}
+	for _, t := range templates {
+		isTemplate[t.Host] = true
+	}
+
+	uuids, descriptions := make([]string, len(triggers)), make([]string, len(triggers))
+	for i, trigger := range triggers {
+		descriptions[i] = trigger.Description
+		if trigger.UUID != "" {
+			uuids[i] = trigger.UUID
+			continue
+		}
+		for _, m := range expressionHosts.FindAllStringSubmatch(trigger.Expression, -1) {
+			if isTemplate[m[1]] {
+				triggers[i].UUID = templateUUID(trigger.Description, trigger.Expression, trigger.RecoveryExpression)
+				break
+			}
+		}
+		uuids[i] = triggers[i].UUID
+	}
+	return checkUUIDs("triggers", uuids, descriptions)
+}
+
+// fillGraphUUIDs sets the uuid of template graphs missing one,
+// a graph belongs to a template when its items do
+func (api *API) fillGraphUUIDs(graphs Graphs, prototypes bool) (err error) {
+	if !api.templateUUIDs() {
+		return
+	}
+	itemIDs := []string{}
+	for _, graph := range graphs {
+		if graph.UUID != "" {
+			continue
+		}
+		for _, gitem := range graph.GraphItems {
+			itemIDs = append(itemIDs, gitem.ItemID)
+		}
+	}
+	if len(itemIDs) == 0 {
+		return
+	}
+
+	params := Params{"itemids": itemIDs, "output": []string{"itemid", "hostid"}}
+	items, err := api.ItemsGet(params)
+	if err == nil && prototypes {
+		var protos Items
+		protos, err = api.ProtoItemsGet(Params{"itemids": itemIDs, "output": []string{"itemid", "hostid"}})
+		items = append(items, protos...)
+	}
+	if err != nil {
+		return
+	}
+	itemHosts := map[string]string{}
+	hostIDs := []string{}
+	for _, item := range items {
+		itemHosts[item.ItemID] = item.HostID
+		hostIDs = append(hostIDs, item.HostID)
+	}
+	names, err := api.templateNames(hostIDs)
+	if err != nil {
+		return
+	}
+
+	uuids, graphNames := make([]string, len(graphs)), make([]string, len(graphs))
+	for i, graph := range graphs {
+		graphNames[i] = graph.Name
+		if graph.UUID != "" {
+			uuids[i] = graph.UUID
+			continue
+		}
+		seen := map[string]bool{}
+		seed := []string{graph.Name}
+		for _, gitem := range graph.GraphItems {
+			if name, ok := names[itemHosts[gitem.ItemID]]; ok && !seen[name] {
+				seen[name] = true
+				seed = append(seed, name)
+			}
+		}
+		if len(seed) > 1 {
+			graphs[i].UUID = templateUUID(seed...)
+		}
+		uuids[i] = graphs[i].UUID
+	}
+	return checkUUIDs("graphs", uuids, graphNames)
+}
@@ -0,0 +1,34 @@
+package zabbix
+
+// ValueMapping maps an item value to a human readable one
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/valuemap/object#value-mappings
+type ValueMapping struct {
+	Type     string `json:"type,omitempty"`
+	Value    string `json:"value"`
+	NewValue string `json:"newvalue"`
+}
+
+// ValueMappings is an array of ValueMapping
+type ValueMappings []ValueMapping
+
+// ValueMap represent Zabbix value map object
+// https://www.zabbix.com/documentation/6.0/manual/api/reference/valuemap/object
+type ValueMap struct {
+	ValueMapID string        `json:"valuemapid,omitempty"`
+	Name       string        `json:"name"`
+	Mappings   ValueMappings `json:"mappings,omitempty"`
+}
+
+// NewValue returns the mapped value of value, or value itself when unmapped.
+// Only exact matches are considered.
+func (m *ValueMap) NewValue(value string) string {
+	if m == nil {
+		return value
+	}
+	for _, mapping := range m.Mappings {
+		if (mapping.Type == "" || mapping.Type == "0") && mapping.Value == value {
+			return mapping.NewValue
+		}
+	}
+	return value
+}
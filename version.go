@@ -0,0 +1,167 @@
+package zabbix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// ParseVersion converts a version string like "6.0.21" to 60021.
+// Suffixes like "7.0.0alpha1" are ignored.
+func ParseVersion(s string) (v int, err error) {
+	parts := strings.SplitN(s, ".", 3)
+	if len(parts) < 2 {
+		return 0, fmt.Errorf("Unexpected version %q", s)
+	}
+	for i, mult := range []int{10000, 100, 1} {
+		if i >= len(parts) {
+			break
+		}
+		digits := strings.TrimRightFunc(parts[i], func(r rune) bool { return r < '0' || r > '9' })
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			return 0, fmt.Errorf("Unexpected version %q", s)
+		}
+		v += n * mult
+	}
+	return
+}
+
+// ServerVersion returns the server version as major*10000+minor*100+patch, 60021 for 6.0.21.
+// Config.Version is used when set, otherwise the server is asked once and the result is kept.
+// Unlike Version, it does not modify the API structure.
+func (api *API) ServerVersion() (v int, err error) {
+	if api.Config.Version != 0 {
+		return api.Config.Version, nil
+	}
+	if v = int(atomic.LoadInt32(&api.version)); v != 0 {
+		return
+	}
+
+	var s string
+	err = api.callWithErrorParseAuth("apiinfo.version", Params{}, &s, "")
+	if e, ok := err.(*Error); ok && e.isAuthError() && api.Auth != "" {
+		err = api.callWithErrorParseAuth("apiinfo.version", Params{}, &s, api.Auth)
+	}
+	if err != nil {
+		return
+	}
+
+	if v, err = ParseVersion(s); err != nil {
+		return
+	}
+	atomic.StoreInt32(&api.version, int32(v))
+	return
+}
+
+// methodVersions is the range of server versions, since included and until excluded
+// (0 for none), supporting the API methods wrapped by this package
+var methodVersions = map[string][2]int{
+	"apiinfo.version":             {0, 0},
+	"user.login":                  {0, 0},
+	"user.logout":                 {0, 0},
+	"user.get":                    {0, 0},
+	"user.checkAuthentication":    {0, 0},
+	"action.get":                  {0, 0},
+	"action.create":               {0, 0},
+	"action.update":               {0, 0},
+	"action.delete":               {0, 0},
+	"application.get":             {0, 50400},
+	"application.create":          {0, 50400},
+	"application.delete":          {0, 50400},
+	"auditlog.get":                {50400, 0},
+	"dashboard.get":               {0, 0},
+	"dashboard.create":            {0, 0},
+	"dashboard.update":            {0, 0},
+	"dashboard.delete":            {0, 0},
+	"configuration.import":        {0, 0},
+	"configuration.importcompare": {60000, 0},
+	"discoveryrule.get":           {0, 0},
+	"discoveryrule.create":        {0, 0},
+	"discoveryrule.update":        {0, 0},
+	"discoveryrule.delete":        {0, 0},
+	"event.get":                   {0, 0},
+	"event.acknowledge":           {0, 0},
+	"graph.get":                   {0, 0},
+	"graph.create":                {0, 0},
+	"graph.update":                {0, 0},
+	"graph.delete":                {0, 0},
+	"graphprototype.get":          {0, 0},
+	"graphprototype.create":       {0, 0},
+	"graphprototype.update":       {0, 0},
+	"graphprototype.delete":       {0, 0},
+	"history.get":                 {0, 0},
+	"history.push":                {70000, 0},
+	"host.get":                    {0, 0},
+	"host.create":                 {0, 0},
+	"host.update":                 {0, 0},
+	"host.massupdate":             {0, 0},
+	"host.delete":                 {0, 0},
+	"hostgroup.get":               {0, 0},
+	"hostgroup.create":            {0, 0},
+	"hostgroup.update":            {0, 0},
+	"hostgroup.massadd":           {0, 0},
+	"hostgroup.delete":            {0, 0},
+	"hostinterface.get":           {0, 0},
+	"hostinterface.create":        {0, 0},
+	"hostinterface.update":        {0, 0},
+	"hostinterface.delete":        {0, 0},
+	"hostprototype.get":           {0, 0},
+	"hostprototype.create":        {0, 0},
+	"httptest.get":                {0, 0},
+	"httptest.create":             {0, 0},
+	"httptest.update":             {0, 0},
+	"httptest.delete":             {0, 0},
+	"item.get":                    {0, 0},
+	"item.create":                 {0, 0},
+	"item.update":                 {0, 0},
+	"item.delete":                 {0, 0},
+	"itemprototype.get":           {0, 0},
+	"itemprototype.create":        {0, 0},
+	"itemprototype.update":        {0, 0},
+	"itemprototype.delete":        {0, 0},
+	"problem.get":                 {30400, 0},
+	"proxy.get":                   {0, 0},
+	"proxy.create":                {0, 0},
+	"proxy.update":                {0, 0},
+	"proxy.delete":                {0, 0},
+	"sla.getsli":                  {60000, 0},
+	"template.get":                {0, 0},
+	"template.create":             {0, 0},
+	"template.update":             {0, 0},
+	"template.delete":             {0, 0},
+	"templatedashboard.get":       {50200, 0},
+	"templatedashboard.create":    {50200, 0},
+	"templatedashboard.update":    {50200, 0},
+	"templatedashboard.delete":    {50200, 0},
+	"trigger.get":                 {0, 0},
+	"trigger.create":              {0, 0},
+	"trigger.update":              {0, 0},
+	"trigger.delete":              {0, 0},
+	"triggerprototype.get":        {0, 0},
+	"triggerprototype.create":     {0, 0},
+	"triggerprototype.update":     {0, 0},
+	"triggerprototype.delete":     {0, 0},
+	"usermacro.get":               {0, 0},
+	"usermacro.create":            {0, 0},
+	"usermacro.update":            {0, 0},
+	"usermacro.delete":            {0, 0},
+	"usermacro.createglobal":      {0, 0},
+	"usermacro.updateglobal":      {0, 0},
+	"usermacro.deleteglobal":      {0, 0},
+}
+
+// AvailableMethods Reports which API methods wrapped by this package the server version supports.
+// It is derived from the version only, user role restrictions are not taken into account.
+func (api *API) AvailableMethods() (res map[string]bool, err error) {
+	v, err := api.ServerVersion()
+	if err != nil {
+		return
+	}
+	res = make(map[string]bool, len(methodVersions))
+	for method, versions := range methodVersions {
+		res[method] = v >= versions[0] && (versions[1] == 0 || v < versions[1])
+	}
+	return
+}